@@ -10,6 +10,7 @@
         "omit" (list
           "events.nats.token"
           "accessTokenProvider.source.clientCredentials.clientSecret"
+          "introspection.clientSecret"
         )
     )
 }}
@@ -13,4 +13,7 @@ stringData:
   {{- with $values.config.accessTokenProvider.source.clientCredentials.clientSecret }}
   IAMRUNTIME_ACCESSTOKENPROVIDER_SOURCE_CLIENTCREDENTIALS_CLIENTSECRET: {{ quote . }}
   {{- end }}
+  {{- with $values.config.introspection.clientSecret }}
+  IAMRUNTIME_INTROSPECTION_CLIENTSECRET: {{ quote . }}
+  {{- end }}
 {{- end }}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/config"
+	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+)
+
+// reloader applies configuration changes to the running components on SIGHUP and when the
+// config file changes.
+type reloader struct {
+	mu sync.Mutex
+
+	v       *viper.Viper
+	current config.Config
+
+	validator   jwt.ReloadableValidator
+	tokenSource accesstoken.ReloadableTokenSource
+	permClient  permissions.Client
+}
+
+// run reloads the configuration until the context is canceled.
+func (r *reloader) run(ctx context.Context) {
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	defer signal.Stop(hupCh)
+
+	fileCh := r.watchConfigFile(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupCh:
+			logger.Info("SIGHUP received, reloading configuration")
+		case <-fileCh:
+			logger.Info("config file changed, reloading configuration")
+		}
+
+		r.reload()
+	}
+}
+
+// watchConfigFile returns a channel receiving a value whenever the config file changes.
+// The parent directory is watched so replacements, such as Kubernetes ConfigMap symlink swaps, are detected.
+func (r *reloader) watchConfigFile(ctx context.Context) <-chan struct{} {
+	out := make(chan struct{}, 1)
+
+	file := r.v.ConfigFileUsed()
+	if file == "" {
+		return out
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Warnw("failed to create config file watcher, only SIGHUP reloads are available", "error", err)
+
+		return out
+	}
+
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		_ = watcher.Close() //nolint:errcheck // error check not needed
+
+		logger.Warnw("failed to watch config file, only SIGHUP reloads are available", "error", err)
+
+		return out
+	}
+
+	go func() {
+		defer watcher.Close() //nolint:errcheck // error check not needed
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+
+				// Drop the notification if a reload is already pending.
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Warnw("config file watcher error", "error", err)
+			}
+		}
+	}()
+
+	return out
+}
+
+// reload reads the configuration and applies the reloadable settings. The reload is rejected when
+// settings which cannot be applied while running have changed.
+func (r *reloader) reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.v.ConfigFileUsed() != "" {
+		if err := r.v.ReadInConfig(); err != nil {
+			logger.Errorw("configuration reload failed, unable to read config file", "error", err)
+
+			return
+		}
+	}
+
+	var updated config.Config
+
+	if err := r.v.Unmarshal(&updated); err != nil {
+		logger.Errorw("configuration reload failed, unable to process config", "error", err)
+
+		return
+	}
+
+	if changes := config.NonReloadableChanges(r.current, updated); len(changes) != 0 {
+		logger.Errorw("configuration reload rejected, changed settings require a restart to be applied",
+			"settings", changes,
+		)
+
+		return
+	}
+
+	setLogLevel()
+
+	if !reflect.DeepEqual(r.current.JWT, updated.JWT) {
+		if err := r.validator.Reload(updated.JWT); err != nil {
+			logger.Errorw("failed to reload jwt configuration, previous configuration remains in use", "error", err)
+
+			updated.JWT = r.current.JWT
+		} else {
+			logger.Info("jwt configuration reloaded")
+		}
+	}
+
+	if !reflect.DeepEqual(r.current.AccessToken, updated.AccessToken) {
+		if err := r.tokenSource.Reload(updated.AccessToken); err != nil {
+			logger.Errorw("failed to reload access token configuration, previous configuration remains in use", "error", err)
+
+			updated.AccessToken = r.current.AccessToken
+		} else {
+			logger.Info("access token configuration reloaded")
+		}
+	}
+
+	if !reflect.DeepEqual(r.current.Permissions.Discovery, updated.Permissions.Discovery) {
+		if err := r.permClient.Reconfigure(updated.Permissions); err != nil {
+			logger.Errorw("failed to reload permissions discovery hosts, previous hosts remain in use", "error", err)
+
+			updated.Permissions.Discovery = r.current.Permissions.Discovery
+		} else {
+			logger.Info("permissions discovery hosts reloaded")
+		}
+	}
+
+	r.current = updated
+
+	logger.Info("configuration reloaded")
+}
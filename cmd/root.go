@@ -2,6 +2,9 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"strings"
 
 	"go.infratographer.com/iam-runtime-infratographer/internal/config"
@@ -14,10 +17,32 @@ import (
 
 const appName = "iam-runtime-infratographer"
 
+// Process exit codes.
+const (
+	// exitCodeOK is returned when the server shut down gracefully.
+	exitCodeOK = 0
+	// exitCodeError is returned when the server failed to start or failed while running.
+	exitCodeError = 1
+	// exitCodeShutdownTimeout is returned when in-flight requests did not complete within the
+	// shutdown timeout and the server was stopped forcefully.
+	exitCodeShutdownTimeout = 2
+)
+
+// exitError is returned by commands which exit with a specific exit code.
+type exitError struct {
+	code int
+}
+
+// Error implements error.
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
 var (
 	cfgFile   string
 	appConfig config.Config
 	logger    *zap.SugaredLogger
+	logLevel  = zap.NewAtomicLevel()
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -30,7 +55,13 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	err := rootCmd.Execute()
+
+	if exitErr := new(exitError); errors.As(err, &exitErr) {
+		os.Exit(exitErr.code)
+	}
+
+	cobra.CheckErr(err)
 }
 
 func init() {
@@ -82,11 +113,9 @@ func setupLogging() {
 		cfg = zap.NewDevelopmentConfig()
 	}
 
-	if viper.GetBool("logging.debug") {
-		cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
-	} else {
-		cfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
-	}
+	setLogLevel()
+
+	cfg.Level = logLevel
 
 	l, err := cfg.Build()
 	if err != nil {
@@ -97,6 +126,15 @@ func setupLogging() {
 	defer logger.Sync() //nolint:errcheck
 }
 
+// setLogLevel sets the level of the logger from the current configuration.
+func setLogLevel() {
+	if viper.GetBool("logging.debug") {
+		logLevel.SetLevel(zap.DebugLevel)
+	} else {
+		logLevel.SetLevel(zap.InfoLevel)
+	}
+}
+
 // viperBindFlag provides a wrapper around the viper bindings that handles error checks
 func viperBindFlag(name string, flag *pflag.Flag) {
 	if err := viper.BindPFlag(name, flag); err != nil {
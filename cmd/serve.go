@@ -4,13 +4,18 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"syscall"
 
 	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/audit"
 	"go.infratographer.com/iam-runtime-infratographer/internal/config"
+	"go.infratographer.com/iam-runtime-infratographer/internal/degradation"
 	"go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
+	"go.infratographer.com/iam-runtime-infratographer/internal/introspection"
 	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
 	"go.infratographer.com/iam-runtime-infratographer/internal/otelx"
 	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+	"go.infratographer.com/iam-runtime-infratographer/internal/revocation"
 	"go.infratographer.com/iam-runtime-infratographer/internal/server"
 
 	"github.com/spf13/cobra"
@@ -20,6 +25,9 @@ import (
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "starts the IAM runtime server",
+	// Failures are logged by the server, the exit code reports the outcome.
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		return serve(cmd.Context(), viper.GetViper(), appConfig)
 	},
@@ -32,8 +40,12 @@ func init() {
 
 	otelx.AddFlags(cmdFlags)
 	jwt.AddFlags(cmdFlags)
+	introspection.AddFlags(cmdFlags)
 	permissions.AddFlags(cmdFlags)
 	eventsx.AddFlags(cmdFlags)
+	revocation.AddFlags(cmdFlags)
+	audit.AddFlags(cmdFlags)
+	degradation.AddFlags(cmdFlags)
 	server.AddFlags(cmdFlags)
 	accesstoken.AddFlags(cmdFlags)
 
@@ -42,21 +54,33 @@ func init() {
 	}
 }
 
-func serve(ctx context.Context, _ *viper.Viper, cfg config.Config) error {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+func serve(ctx context.Context, v *viper.Viper, cfg config.Config) error {
+	signalCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
 
-	err := otelx.Initialize(cfg.Tracing, appName)
+	otelLogger, shutdownTelemetry, err := otelx.Initialize(ctx, cfg.Tracing, appName, logger)
 	if err != nil {
 		logger.Fatalw("unable to initialize tracing system", "error", err)
 	}
 
-	validator, err := jwt.NewValidator(cfg.JWT)
+	logger = otelLogger
+
+	// Components run until they are shut down, not until a signal is received, so requests
+	// in-flight while draining can still be served.
+	componentCtx, stopComponents := context.WithCancel(ctx)
+	defer stopComponents()
+
+	validator, err := jwt.NewReloadableValidator(cfg.JWT, logger)
 	if err != nil {
 		logger.Fatalw("failed to create validator", "error", err)
 	}
 
-	tokenSource, err := accesstoken.NewTokenSource(ctx, cfg.AccessToken)
+	introspector, err := introspection.NewIntrospector(componentCtx, cfg.Introspection)
+	if err != nil {
+		logger.Fatalw("failed to create introspector", "error", err)
+	}
+
+	tokenSource, err := accesstoken.NewReloadableTokenSource(componentCtx, cfg.AccessToken)
 	if err != nil {
 		logger.Fatalw("failed to configure token source", "error", err)
 	}
@@ -71,22 +95,97 @@ func serve(ctx context.Context, _ *viper.Viper, cfg config.Config) error {
 		logger.Fatalw("failed to create events publisher", "error", err)
 	}
 
-	iamSrv, err := server.NewServer(cfg.Server, validator, permClient, publisher, tokenSource, logger)
+	revocationChecker, err := revocation.NewChecker(componentCtx, cfg.Revocation, publisher, logger)
+	if err != nil {
+		logger.Fatalw("failed to create revocation checker", "error", err)
+	}
+
+	auditor, err := audit.NewAuditor(cfg.Audit, publisher, logger)
+	if err != nil {
+		logger.Fatalw("failed to create auditor", "error", err)
+	}
+
+	degradationPolicy, err := degradation.NewPolicy(cfg.Degradation)
+	if err != nil {
+		logger.Fatalw("failed to create degradation policy", "error", err)
+	}
+
+	iamSrv, err := server.NewServer(cfg.Server, validator, introspector, permClient, publisher, revocationChecker, auditor, degradationPolicy, tokenSource, logger)
 	if err != nil {
 		logger.Fatalw("failed to create server", "error", err)
 	}
 
+	reloadCtx, stopReload := context.WithCancel(componentCtx)
+	defer stopReload()
+
+	configReloader := &reloader{
+		v:           v,
+		current:     cfg,
+		validator:   validator,
+		tokenSource: tokenSource,
+		permClient:  permClient,
+	}
+
+	go configReloader.run(reloadCtx)
+
+	listenErr := make(chan error, 1)
+
 	go func() {
-		if err := iamSrv.Listen(); err != nil {
-			logger.Fatalw("failed starting server", "error", err)
-		}
+		listenErr <- iamSrv.Listen()
 	}()
 
-	<-c
+	exitCode := exitCodeOK
 
-	logger.Info("signal received, stopping server")
+	select {
+	case <-signalCtx.Done():
+		logger.Info("signal received, shutting down")
+	case err := <-listenErr:
+		logger.Errorw("server failed, shutting down", "error", err)
 
-	iamSrv.Stop()
+		exitCode = exitCodeError
+	}
+
+	// Restore the default signal behavior so a second signal terminates immediately.
+	stopSignals()
+
+	if err := iamSrv.Shutdown(ctx); err != nil {
+		logger.Errorw("server did not shut down gracefully", "error", err)
+
+		if exitCode == exitCodeOK {
+			exitCode = exitCodeShutdownTimeout
+		}
+	}
+
+	// The remaining components are shut down in dependency order: the audit sinks publish events,
+	// and the revocation checker subscribes to events, so both are stopped before the publisher.
+	cleanupCtx, cancelCleanup := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+	defer cancelCleanup()
+
+	stopReload()
+
+	if err := auditor.Close(); err != nil {
+		logger.Warnw("failed to close audit sinks", "error", err)
+	}
+
+	stopComponents()
+
+	if err := publisher.Close(cleanupCtx); err != nil {
+		logger.Warnw("failed to close events publisher", "error", err)
+	}
+
+	permClient.Close()
+	tokenSource.Close()
+	validator.Close()
+
+	if err := shutdownTelemetry(cleanupCtx); err != nil {
+		logger.Warnw("failed to flush telemetry", "error", err)
+	}
+
+	logger.Infow("shutdown complete", "exit_code", exitCode)
+
+	if exitCode != exitCodeOK {
+		return &exitError{code: exitCode}
+	}
 
 	return nil
 }
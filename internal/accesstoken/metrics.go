@@ -0,0 +1,27 @@
+package accesstoken
+
+import (
+	"golang.org/x/oauth2"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+)
+
+// metricsTokenSource records every token fetched from the source.
+// When wrapped by a reuse token source, tokens are only fetched when the previous token expires.
+type metricsTokenSource struct {
+	source oauth2.TokenSource
+}
+
+// Token implements oauth2.TokenSource.
+func (s *metricsTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		metrics.IncTokenSourceRefreshes("error")
+
+		return nil, err
+	}
+
+	metrics.IncTokenSourceRefreshes("success")
+
+	return token, nil
+}
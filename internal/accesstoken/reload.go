@@ -0,0 +1,90 @@
+package accesstoken
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+
+	"golang.org/x/oauth2"
+)
+
+// ReloadableTokenSource is a token source whose configuration can be replaced while in use.
+type ReloadableTokenSource interface {
+	HealthyTokenSource
+
+	// Reload creates a token source with the new configuration and atomically replaces the current
+	// token source, closing the replaced token source if it implements [io.Closer]. The current
+	// token source remains in use when the new configuration is invalid.
+	Reload(cfg Config) error
+
+	// Close cancels the context token sources use to request tokens and closes the current token source.
+	Close()
+}
+
+type reloadableTokenSource struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	current atomic.Pointer[HealthyTokenSource]
+}
+
+// NewReloadableTokenSource creates a reloadable token source from the provided config.
+// Token sources request tokens using the provided context until the token source is closed.
+func NewReloadableTokenSource(ctx context.Context, cfg Config) (ReloadableTokenSource, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	out := &reloadableTokenSource{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if err := out.Reload(cfg); err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Reload creates a token source with the new configuration and atomically replaces the current
+// token source, closing the replaced token source if it implements [io.Closer]. The current
+// token source remains in use when the new configuration is invalid.
+func (r *reloadableTokenSource) Reload(cfg Config) error {
+	ts, err := NewTokenSource(r.ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if old := r.current.Swap(&ts); old != nil {
+		closeTokenSource(*old)
+	}
+
+	return nil
+}
+
+// closeTokenSource closes the token source if it implements [io.Closer].
+// The token source has already been replaced, so a failure to close it is not returned.
+func closeTokenSource(ts HealthyTokenSource) {
+	if closer, ok := ts.(io.Closer); ok {
+		_ = closer.Close() //nolint:errcheck // error check not needed
+	}
+}
+
+// Token returns a token from the current token source.
+func (r *reloadableTokenSource) Token() (*oauth2.Token, error) {
+	return (*r.current.Load()).Token()
+}
+
+// HealthCheck returns nil when the current token source is healthy.
+func (r *reloadableTokenSource) HealthCheck(ctx context.Context) error {
+	return (*r.current.Load()).HealthCheck(ctx)
+}
+
+// Close cancels the context token sources use to request tokens and closes the current token source.
+func (r *reloadableTokenSource) Close() {
+	r.cancel()
+
+	if current := r.current.Load(); current != nil {
+		closeTokenSource(*current)
+	}
+}
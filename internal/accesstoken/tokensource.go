@@ -35,7 +35,7 @@ func (c Config) toTokenSource(ctx context.Context) (oauth2.TokenSource, error) {
 		}
 	}
 
-	source = oauth2.ReuseTokenSourceWithExpiry(nil, source, c.ExpiryDelta)
+	source = oauth2.ReuseTokenSourceWithExpiry(nil, &metricsTokenSource{source}, c.ExpiryDelta)
 
 	return source, nil
 }
@@ -72,29 +72,14 @@ func (c ClientCredentialConfig) toTokenSource(ctx context.Context) (oauth2.Token
 		return nil, fmt.Errorf("failed to fetch issuer token endpoint: %w", err)
 	}
 
-	clientID := c.ClientID
-	clientSecret := c.ClientSecret
-
-	if uri, err := url.ParseRequestURI(clientID); err == nil && uri.Scheme == "file" {
-		file := filepath.Join(uri.Host, uri.Path)
-
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", file, err)
-		}
-
-		clientID = strings.TrimSpace(string(content))
+	clientID, err := ReadSecret(c.ClientID)
+	if err != nil {
+		return nil, err
 	}
 
-	if uri, err := url.ParseRequestURI(clientSecret); err == nil && uri.Scheme == "file" {
-		file := filepath.Join(uri.Host, uri.Path)
-
-		content, err := os.ReadFile(file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", file, err)
-		}
-
-		clientSecret = strings.TrimSpace(string(content))
+	clientSecret, err := ReadSecret(c.ClientSecret)
+	if err != nil {
+		return nil, err
 	}
 
 	config := clientcredentials.Config{
@@ -106,6 +91,23 @@ func (c ClientCredentialConfig) toTokenSource(ctx context.Context) (oauth2.Token
 	return config.TokenSource(ctx), nil
 }
 
+// ReadSecret returns the value, or the trimmed contents of the file if the value uses the file:// scheme.
+func ReadSecret(value string) (string, error) {
+	uri, err := url.ParseRequestURI(value)
+	if err != nil || uri.Scheme != "file" {
+		return value, nil
+	}
+
+	file := filepath.Join(uri.Host, uri.Path)
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", file, err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
 func (c ExchangeConfig) toTokenSource(ctx context.Context, upstream oauth2.TokenSource) (oauth2.TokenSource, error) {
 	if err := c.Validate(); err != nil {
 		return nil, err
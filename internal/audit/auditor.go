@@ -0,0 +1,215 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+)
+
+// Auditor records authorization decisions.
+type Auditor interface {
+	// Record queues the event to be written to every sink, subject to sampling. Failures are logged
+	// and do not affect the decision.
+	Record(ctx context.Context, event Event)
+
+	// Close writes the queued events, then flushes and closes all sinks.
+	Close() error
+}
+
+// queuedEvent is an encoded event waiting to be written to the sinks.
+type queuedEvent struct {
+	ctx  context.Context
+	data []byte
+}
+
+type auditor struct {
+	enabled      bool
+	sampleRatio  float64
+	queueTimeout time.Duration
+	dropWhenFull bool
+	sinks        map[string]Sink
+	logger       *zap.SugaredLogger
+	sample       func() float64
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan queuedEvent
+	done   chan struct{}
+}
+
+// NewAuditor creates an auditor writing to the sinks enabled in the config.
+// Events are published with the publisher when a subject is configured.
+func NewAuditor(config Config, publisher Publisher, logger *zap.SugaredLogger) (Auditor, error) {
+	if !config.Enabled {
+		return &auditor{
+			enabled: false,
+		}, nil
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, ErrInvalidSampleRatio
+	}
+
+	sinks := make(map[string]Sink)
+
+	if config.Stdout {
+		sinks["stdout"] = NewWriterSink(os.Stdout)
+	}
+
+	if config.File.Path != "" {
+		sink, err := NewFileSink(config.File.Path, config.File.MaxSize, config.File.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+
+		sinks["file"] = sink
+	}
+
+	if config.Subject != "" {
+		sinks["nats"] = NewPublisherSink(publisher, config.Subject)
+	}
+
+	if len(sinks) == 0 {
+		return nil, ErrNoSinks
+	}
+
+	return newAuditor(config, sinks, logger), nil
+}
+
+func newAuditor(config Config, sinks map[string]Sink, logger *zap.SugaredLogger) *auditor {
+	config = config.withDefaults()
+
+	a := &auditor{
+		enabled:      true,
+		sampleRatio:  config.SampleRatio,
+		queueTimeout: config.QueueTimeout,
+		dropWhenFull: config.DropWhenFull,
+		sinks:        sinks,
+		logger:       logger.With("component", "audit"),
+		sample:       rand.Float64, //nolint:gosec // sampling does not require a secure random number
+		queue:        make(chan queuedEvent, config.QueueSize),
+		done:         make(chan struct{}),
+	}
+
+	go a.run()
+
+	return a
+}
+
+// Record queues the event to be written to every sink, subject to sampling. Failures are logged
+// and do not affect the decision. While the queue is full, Record waits up to the queue timeout
+// for space, unless drop when full is set. Events which are not queued are lost.
+func (a *auditor) Record(ctx context.Context, event Event) {
+	if !a.enabled {
+		return
+	}
+
+	if event.Outcome == OutcomeAllowed && !event.Degraded && a.sample() >= a.sampleRatio {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		event.TraceID = spanCtx.TraceID().String()
+		event.SpanID = spanCtx.SpanID().String()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		a.logger.Errorw("failed to encode audit event", "error", err)
+
+		return
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return
+	}
+
+	// The request context is canceled once the decision is returned, so only its values are kept.
+	queued := queuedEvent{ctx: context.WithoutCancel(ctx), data: data}
+
+	select {
+	case a.queue <- queued:
+		return
+	default:
+	}
+
+	if !a.dropWhenFull {
+		timer := time.NewTimer(a.queueTimeout)
+		defer timer.Stop()
+
+		select {
+		case a.queue <- queued:
+			return
+		case <-timer.C:
+		}
+	}
+
+	metrics.IncAuditDropped()
+
+	a.logger.Errorw("audit queue full, decision lost from the audit log",
+		"outcome", event.Outcome,
+		"subject", event.Subject,
+	)
+}
+
+// run writes queued events to the sinks until the queue is closed.
+func (a *auditor) run() {
+	defer close(a.done)
+
+	for event := range a.queue {
+		for name, sink := range a.sinks {
+			if err := sink.Write(event.ctx, event.data); err != nil {
+				metrics.IncAuditWriteFailures(name)
+
+				a.logger.Errorw("failed to write audit event", "sink", name, "error", err)
+			}
+		}
+	}
+}
+
+// Close writes the queued events, then flushes and closes all sinks.
+func (a *auditor) Close() error {
+	if !a.enabled {
+		return nil
+	}
+
+	a.mu.Lock()
+
+	if a.closed {
+		a.mu.Unlock()
+
+		return nil
+	}
+
+	a.closed = true
+
+	close(a.queue)
+
+	a.mu.Unlock()
+
+	<-a.done
+
+	var errs []error
+
+	for _, sink := range a.sinks {
+		errs = append(errs, sink.Close())
+	}
+
+	return errors.Join(errs...)
+}
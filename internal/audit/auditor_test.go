@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type testPublisher struct {
+	subject string
+	data    [][]byte
+}
+
+func (p *testPublisher) Publish(_ context.Context, subject string, data []byte) error {
+	p.subject = subject
+	p.data = append(p.data, data)
+
+	return nil
+}
+
+func TestAuditorRecord(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	publisher := &testPublisher{}
+
+	a := newAuditor(Config{SampleRatio: 1}, map[string]Sink{
+		"stdout": NewWriterSink(&buf),
+		"nats":   NewPublisherSink(publisher, "audit.decisions"),
+	}, zap.NewNop().Sugar())
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+
+	ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+
+	a.Record(ctx, Event{
+		Subject:               "idntusr-abc",
+		CredentialFingerprint: Fingerprint("secret-token"),
+		Mode:                  "all",
+		Actions:               []Action{{Action: "read", ResourceID: "tnntten-abc"}},
+		Outcome:               OutcomeDenied,
+		Error:                 Redact("bad token secret-token", "secret-token"),
+		LatencyMS:             1.5,
+	})
+
+	require.NoError(t, a.Close(), "no error expected closing")
+
+	assert.NotContains(t, buf.String(), "secret-token", "expected credential to be redacted")
+	assert.Equal(t, "audit.decisions", publisher.subject, "unexpected subject")
+	require.Len(t, publisher.data, 1, "expected event to be published")
+
+	var event Event
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event), "expected a JSON line")
+
+	assert.Equal(t, spanCtx.TraceID().String(), event.TraceID, "expected trace id to be recorded")
+	assert.Equal(t, spanCtx.SpanID().String(), event.SpanID, "expected span id to be recorded")
+	assert.Equal(t, "bad token [REDACTED]", event.Error, "unexpected error")
+	assert.False(t, event.Time.IsZero(), "expected time to be set")
+	assert.JSONEq(t, buf.String(), string(publisher.data[0]), "expected sinks to receive the same event")
+}
+
+func TestAuditorSampling(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		outcome      string
+		sample       float64
+		expectRecord bool
+	}{
+		{"allowed sampled", OutcomeAllowed, 0.2, true},
+		{"allowed not sampled", OutcomeAllowed, 0.7, false},
+		{"denied always recorded", OutcomeDenied, 0.7, true},
+		{"error always recorded", OutcomeError, 0.7, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			a := newAuditor(Config{SampleRatio: 0.5}, map[string]Sink{"stdout": NewWriterSink(&buf)}, zap.NewNop().Sugar())
+			a.sample = func() float64 { return tc.sample }
+
+			a.Record(context.Background(), Event{Outcome: tc.outcome})
+
+			require.NoError(t, a.Close(), "no error expected closing")
+
+			if tc.expectRecord {
+				assert.NotEmpty(t, buf.String(), "expected event to be recorded")
+			} else {
+				assert.Empty(t, buf.String(), "expected event to be sampled out")
+			}
+		})
+	}
+}
+
+type blockingSink struct {
+	release chan struct{}
+	written chan []byte
+}
+
+func (s *blockingSink) Write(ctx context.Context, data []byte) error {
+	<-s.release
+
+	s.written <- data
+
+	return ctx.Err()
+}
+
+func (s *blockingSink) Close() error {
+	return nil
+}
+
+func TestAuditorQueue(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		config         Config
+		releaseAfter   time.Duration
+		expectSubjects []string
+	}{
+		{
+			"wait for space",
+			Config{SampleRatio: 1, QueueSize: 1, QueueTimeout: 5 * time.Second},
+			50 * time.Millisecond,
+			[]string{"first", "second", "third"},
+		},
+		{
+			"queue timeout",
+			Config{SampleRatio: 1, QueueSize: 1, QueueTimeout: 10 * time.Millisecond},
+			time.Second,
+			[]string{"first", "second"},
+		},
+		{
+			"drop when full",
+			Config{SampleRatio: 1, QueueSize: 1, QueueTimeout: 5 * time.Second, DropWhenFull: true},
+			time.Second,
+			[]string{"first", "second"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sink := &blockingSink{
+				release: make(chan struct{}),
+				written: make(chan []byte, 3),
+			}
+
+			a := newAuditor(tc.config, map[string]Sink{"blocking": sink}, zap.NewNop().Sugar())
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			// The first event is taken by the writer and the second is queued, filling the queue.
+			a.Record(ctx, Event{Outcome: OutcomeDenied, Subject: "first"})
+
+			assert.Eventually(t, func() bool { return len(a.queue) == 0 }, time.Second, time.Millisecond, "expected writer to take the first event")
+
+			a.Record(ctx, Event{Outcome: OutcomeDenied, Subject: "second"})
+
+			release := time.AfterFunc(tc.releaseAfter, func() { close(sink.release) })
+
+			// The third event waits for space until the sink catches up, the queue times out or is dropped.
+			a.Record(ctx, Event{Outcome: OutcomeDenied, Subject: "third"})
+
+			// Canceling the request context does not prevent queued events from being written.
+			cancel()
+
+			if release.Stop() {
+				close(sink.release)
+			}
+
+			require.NoError(t, a.Close(), "no error expected closing")
+
+			a.Record(context.Background(), Event{Outcome: OutcomeDenied, Subject: "closed"})
+
+			close(sink.written)
+
+			var subjects []string
+
+			for data := range sink.written {
+				var event Event
+
+				require.NoError(t, json.Unmarshal(data, &event), "expected a JSON event")
+
+				subjects = append(subjects, event.Subject)
+			}
+
+			assert.Equal(t, tc.expectSubjects, subjects, "unexpected events written")
+		})
+	}
+}
+
+func TestNewAuditor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		config      Config
+		expectError error
+	}{
+		{"disabled", Config{}, nil},
+		{"no sinks", Config{Enabled: true, SampleRatio: 1}, ErrNoSinks},
+		{"invalid sample ratio", Config{Enabled: true, SampleRatio: 2, Stdout: true}, ErrInvalidSampleRatio},
+		{"stdout", Config{Enabled: true, SampleRatio: 1, Stdout: true}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, err := NewAuditor(tc.config, &testPublisher{}, zap.NewNop().Sugar())
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+
+			a.Record(context.Background(), Event{Outcome: OutcomeDenied})
+
+			assert.NoError(t, a.Close(), "no error expected closing")
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, Fingerprint(""), "expected no fingerprint for empty credential")
+	assert.Equal(t, Fingerprint("token"), Fingerprint("token"), "expected fingerprint to be stable")
+	assert.NotEqual(t, Fingerprint("token"), Fingerprint("other"), "expected fingerprints to differ")
+	assert.NotContains(t, Fingerprint("token"), "token", "expected credential to not be included")
+}
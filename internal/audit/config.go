@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultQueueSize    = 1000
+	defaultQueueTimeout = time.Second
+)
+
+// Config represents the configuration for the authorization decision audit log.
+type Config struct {
+	// Enabled enables recording authorization decisions.
+	Enabled bool
+
+	// SampleRatio is the ratio of allowed decisions which are recorded (0.0 - 1.0).
+	// Denied, failed and degraded decisions are always recorded.
+	// Default: 1.0
+	SampleRatio float64
+
+	// Stdout writes decisions to stdout as JSON lines.
+	Stdout bool
+
+	// File configures writing decisions to a JSON lines file.
+	File FileConfig
+
+	// Subject is the NATS subject decisions are published on. Requires events to be enabled.
+	Subject string
+
+	// QueueSize is the number of decisions buffered while waiting to be written to the sinks.
+	// Decisions are written in the background so sinks do not add latency to requests.
+	// Default: 1000
+	QueueSize int
+
+	// QueueTimeout is how long recording a decision waits for space in a full queue, delaying the
+	// response. Decisions which do not fit within the timeout are lost from the audit log.
+	// Default: 1s
+	QueueTimeout time.Duration
+
+	// DropWhenFull drops decisions immediately while the queue is full instead of waiting for space.
+	// Dropped decisions are lost from the audit log.
+	DropWhenFull bool
+}
+
+func (c Config) withDefaults() Config {
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultQueueSize
+	}
+
+	if c.QueueTimeout <= 0 {
+		c.QueueTimeout = defaultQueueTimeout
+	}
+
+	return c
+}
+
+// FileConfig represents the configuration for the audit log file.
+type FileConfig struct {
+	// Path is the path of the audit log file. Decisions are not written to a file when empty.
+	Path string
+
+	// MaxSize is the size in megabytes at which the file is rotated.
+	// Default: 100
+	MaxSize int
+
+	// MaxBackups is the number of rotated files which are kept.
+	// Default: 5
+	MaxBackups int
+}
+
+// AddFlags sets the command line flags for the audit log.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool("audit.enabled", false, "enable recording authorization decisions")
+	flags.Float64("audit.sampleratio", 1.0, "ratio of allowed decisions recorded (0.0 - 1.0), denied, failed and degraded decisions are always recorded")
+	flags.Bool("audit.stdout", false, "write authorization decisions to stdout")
+	flags.String("audit.file.path", "", "path of the authorization decision audit log file")
+	flags.Int("audit.file.maxsize", 100, "size in megabytes at which the audit log file is rotated") //nolint:mnd
+	flags.Int("audit.file.maxbackups", 5, "number of rotated audit log files kept")                  //nolint:mnd
+	flags.String("audit.subject", "", "NATS subject authorization decisions are published on (requires events to be enabled)")
+	flags.Int("audit.queuesize", defaultQueueSize, "number of authorization decisions buffered while waiting to be written")
+	flags.Duration("audit.queuetimeout", defaultQueueTimeout, "how long recording an authorization decision waits for space in a full queue before it is lost")
+	flags.Bool("audit.dropwhenfull", false, "drop authorization decisions immediately while the queue is full, losing them from the audit log")
+}
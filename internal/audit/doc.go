@@ -0,0 +1,2 @@
+// Package audit records authorization decisions to pluggable sinks.
+package audit
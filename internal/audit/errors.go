@@ -0,0 +1,11 @@
+package audit
+
+import "errors"
+
+var (
+	// ErrNoSinks is returned when auditing is enabled without any sinks configured.
+	ErrNoSinks = errors.New("audit logging requires at least one sink")
+
+	// ErrInvalidSampleRatio is returned when the sample ratio is not between 0 and 1.
+	ErrInvalidSampleRatio = errors.New("audit sample ratio must be between 0.0 and 1.0")
+)
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	// OutcomeAllowed is the outcome of a decision which allowed all actions.
+	OutcomeAllowed = "allowed"
+
+	// OutcomeDenied is the outcome of a decision which denied at least one action.
+	OutcomeDenied = "denied"
+
+	// OutcomeError is the outcome of a request which failed before a decision was made.
+	OutcomeError = "error"
+
+	redacted = "[REDACTED]"
+)
+
+// Event is a recorded authorization decision.
+// Credentials are never recorded, only a fingerprint of the credential.
+// Subject is only set when the credential was validated locally, otherwise the subject claim of
+// the unverified credential is recorded as UnverifiedSubject.
+type Event struct {
+	Time                  time.Time `json:"time"`
+	TraceID               string    `json:"trace_id,omitempty"`
+	SpanID                string    `json:"span_id,omitempty"`
+	Subject               string    `json:"subject,omitempty"`
+	UnverifiedSubject     string    `json:"unverified_subject,omitempty"`
+	CredentialFingerprint string    `json:"credential_fingerprint,omitempty"`
+	Mode                  string    `json:"mode"`
+	Actions               []Action  `json:"actions"`
+	Outcome               string    `json:"outcome"`
+	Error                 string    `json:"error,omitempty"`
+	LatencyMS             float64   `json:"latency_ms"`
+
+	// Degraded is set when the decision was made by the degradation policy while permissions-api
+	// was unavailable, rather than by permissions-api.
+	Degraded bool `json:"degraded,omitempty"`
+	// DegradationPolicy is the degradation policy which made a degraded decision.
+	DegradationPolicy string `json:"degradation_policy,omitempty"`
+	// DecisionAgeMS is the age of the decision served by the stale degradation policy.
+	DecisionAgeMS float64 `json:"decision_age_ms,omitempty"`
+}
+
+// Action is an action requested in an authorization decision.
+type Action struct {
+	Action     string `json:"action"`
+	ResourceID string `json:"resource_id"`
+	// Outcome is the outcome of the individual action, set when actions are evaluated individually.
+	Outcome string `json:"outcome,omitempty"`
+}
+
+// Fingerprint returns a fingerprint identifying the credential, allowing decisions for the same
+// credential to be correlated without recording the credential.
+func Fingerprint(credential string) string {
+	if credential == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(credential))
+
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// Redact returns the message with any occurrences of the credential removed.
+func Redact(message, credential string) string {
+	if credential == "" {
+		return message
+	}
+
+	return strings.ReplaceAll(message, credential, redacted)
+}
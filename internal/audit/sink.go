@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const megabyte = 1024 * 1024
+
+// Sink writes encoded audit events.
+type Sink interface {
+	// Write writes a single JSON encoded event.
+	Write(ctx context.Context, data []byte) error
+
+	// Close flushes and closes the sink.
+	Close() error
+}
+
+// Publisher publishes data on a subject.
+type Publisher interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+// jsonLine returns a copy of the data terminated by a newline.
+func jsonLine(data []byte) []byte {
+	line := make([]byte, 0, len(data)+1)
+
+	return append(append(line, data...), '\n')
+}
+
+// writerSink writes events as JSON lines to a writer.
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink creates a sink writing events as JSON lines to the writer.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+func (s *writerSink) Write(_ context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.w.Write(jsonLine(data))
+
+	return err
+}
+
+func (s *writerSink) Close() error {
+	return nil
+}
+
+// fileSink writes events as JSON lines to a file, rotating the file once it reaches the max size.
+// Rotated files are named with a numeric suffix, the most recent being path.1.
+type fileSink struct {
+	mu sync.Mutex
+
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink creates a sink writing events as JSON lines to the file at path.
+// The file is rotated once it reaches maxSize megabytes, keeping maxBackups rotated files.
+func NewFileSink(path string, maxSize, maxBackups int) (Sink, error) {
+	s := &fileSink{
+		path:       filepath.Clean(path),
+		maxSize:    int64(maxSize) * megabyte,
+		maxBackups: maxBackups,
+	}
+
+	file, size, err := openFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.file = file
+	s.size = size
+
+	return s, nil
+}
+
+// openFile opens the file at path for appending, returning its current size.
+func openFile(path string) (*os.File, int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:mnd
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close() //nolint:errcheck // error check not needed
+
+		return nil, 0, fmt.Errorf("failed to stat audit log file: %w", err)
+	}
+
+	return file, info.Size(), nil
+}
+
+func (s *fileSink) Write(_ context.Context, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := jsonLine(data)
+
+	var rotateErr error
+
+	// A failed rotation is retried on the next write, the event is written to the current file.
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		rotateErr = s.rotate()
+	}
+
+	n, err := s.file.Write(line)
+
+	s.size += int64(n)
+
+	return errors.Join(rotateErr, err)
+}
+
+// rotate shifts the existing backups, moves the current file to path.1 and opens a new file.
+// The current file is only closed once the new file is open, so writes continue to the current
+// file when rotation fails.
+func (s *fileSink) rotate() error {
+	if s.maxBackups <= 0 {
+		if err := os.Remove(s.path); err != nil {
+			return fmt.Errorf("failed to remove audit log file: %w", err)
+		}
+	} else {
+		_ = os.Remove(s.backupPath(s.maxBackups)) //nolint:errcheck // the oldest backup may not exist
+
+		for i := s.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to rotate audit log file: %w", err)
+			}
+		}
+
+		if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log file: %w", err)
+		}
+	}
+
+	file, size, err := openFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	closeErr := s.file.Close()
+
+	s.file = file
+	s.size = size
+
+	if closeErr != nil {
+		return fmt.Errorf("failed to close rotated audit log file: %w", closeErr)
+	}
+
+	return nil
+}
+
+func (s *fileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// publisherSink publishes events on a subject.
+type publisherSink struct {
+	publisher Publisher
+	subject   string
+}
+
+// NewPublisherSink creates a sink publishing events on the subject.
+func NewPublisherSink(publisher Publisher, subject string) Sink {
+	return &publisherSink{
+		publisher: publisher,
+		subject:   subject,
+	}
+}
+
+func (s *publisherSink) Write(ctx context.Context, data []byte) error {
+	return s.publisher.Publish(ctx, s.subject, data)
+}
+
+func (s *publisherSink) Close() error {
+	return nil
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSinkRotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path, 1, 2)
+	require.NoError(t, err, "no error expected creating sink")
+
+	t.Cleanup(func() { _ = sink.Close() })
+
+	// Each line is a quarter of a megabyte, so every four writes rotate the file.
+	data := []byte(strings.Repeat("a", megabyte/4-1))
+
+	for range 13 {
+		require.NoError(t, sink.Write(context.Background(), data), "no error expected writing")
+	}
+
+	for _, name := range []string{"audit.log", "audit.log.1", "audit.log.2"} {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		require.NoError(t, err, "expected %s to exist", name)
+
+		assert.LessOrEqual(t, info.Size(), int64(megabyte), "expected %s to not exceed the max size", name)
+	}
+
+	_, err = os.Stat(path + ".3")
+	assert.ErrorIs(t, err, os.ErrNotExist, "expected only max backups to be kept")
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err, "no error expected reading file")
+
+	assert.Equal(t, string(data)+"\n", string(current), "expected the current file to contain the last write")
+}
+
+func TestFileSinkRotationFailure(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path, 1, 1)
+	require.NoError(t, err, "no error expected creating sink")
+
+	t.Cleanup(func() { _ = sink.Close() })
+
+	// A non-empty directory in place of the backup prevents the file from being rotated.
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o700), "no error expected creating directory")
+
+	data := []byte(strings.Repeat("a", megabyte/2-1))
+
+	require.NoError(t, sink.Write(context.Background(), data), "no error expected writing")
+	require.NoError(t, sink.Write(context.Background(), data), "no error expected writing")
+
+	assert.Error(t, sink.Write(context.Background(), data), "expected rotation error")
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err, "no error expected reading file")
+
+	assert.Equal(t, strings.Repeat(string(data)+"\n", 3), string(current), "expected event to be written when rotation fails")
+
+	require.NoError(t, os.RemoveAll(path+".1"), "no error expected removing directory")
+
+	require.NoError(t, sink.Write(context.Background(), data), "expected writes to recover once rotation succeeds")
+
+	current, err = os.ReadFile(path)
+	require.NoError(t, err, "no error expected reading file")
+
+	assert.Equal(t, string(data)+"\n", string(current), "expected the file to be rotated")
+}
+
+func TestFileSinkAppends(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o600), "no error expected writing file")
+
+	sink, err := NewFileSink(path, 1, 1)
+	require.NoError(t, err, "no error expected creating sink")
+
+	require.NoError(t, sink.Write(context.Background(), []byte(`{"a":1}`)), "no error expected writing")
+	require.NoError(t, sink.Close(), "no error expected closing")
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err, "no error expected reading file")
+
+	assert.Equal(t, "{}\n{\"a\":1}\n", string(contents), "expected event to be appended")
+}
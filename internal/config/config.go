@@ -2,19 +2,27 @@ package config
 
 import (
 	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/audit"
+	"go.infratographer.com/iam-runtime-infratographer/internal/degradation"
 	"go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
+	"go.infratographer.com/iam-runtime-infratographer/internal/introspection"
 	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
 	"go.infratographer.com/iam-runtime-infratographer/internal/otelx"
 	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+	"go.infratographer.com/iam-runtime-infratographer/internal/revocation"
 	"go.infratographer.com/iam-runtime-infratographer/internal/server"
 )
 
 // Config represents a configuration for iam-runtime-infratographer.
 type Config struct {
-	JWT         jwt.Config
-	Permissions permissions.Config
-	Events      eventsx.Config
-	Server      server.Config
-	Tracing     otelx.Config
-	AccessToken accesstoken.Config `mapstructure:"accessTokenProvider"`
+	JWT           jwt.Config
+	Introspection introspection.Config
+	Permissions   permissions.Config
+	Events        eventsx.Config
+	Revocation    revocation.Config
+	Audit         audit.Config
+	Degradation   degradation.Config
+	Server        server.Config
+	Tracing       otelx.Config
+	AccessToken   accesstoken.Config `mapstructure:"accessTokenProvider"`
 }
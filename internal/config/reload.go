@@ -0,0 +1,57 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+)
+
+// NonReloadableChanges returns the settings which differ between the configurations and cannot be
+// applied while running. The JWT and access token provider configurations and the preferred and
+// fallback permissions-api discovery hosts can be reloaded, all other changes require a restart.
+func NonReloadableChanges(current, updated Config) []string {
+	for _, c := range []*Config{&current, &updated} {
+		c.JWT = jwt.Config{}
+		c.AccessToken = accesstoken.Config{}
+		c.Permissions.Discovery.Prefer = ""
+		c.Permissions.Discovery.Fallback = ""
+	}
+
+	return diff("", reflect.ValueOf(current), reflect.ValueOf(updated))
+}
+
+// diff returns the paths of the struct fields which differ between the values.
+func diff(path string, a, b reflect.Value) []string {
+	if a.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return nil
+		}
+
+		return []string{path}
+	}
+
+	var changes []string
+
+	for i := range a.NumField() {
+		field := a.Type().Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := strings.ToLower(field.Name)
+		if tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ","); tag != "" {
+			name = tag
+		}
+
+		if path != "" {
+			name = path + "." + name
+		}
+
+		changes = append(changes, diff(name, a.Field(i), b.Field(i))...)
+	}
+
+	return changes
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNonReloadableChanges(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		update        func(c *Config)
+		expectChanges []string
+	}{
+		{"unchanged", func(*Config) {}, nil},
+		{"jwt", func(c *Config) {
+			c.JWT.JWKSRefreshInterval = time.Minute
+			c.JWT.Issuer = "https://issuer.example.com/"
+		}, nil},
+		{"access token scopes", func(c *Config) { c.AccessToken.Exchange.Scopes = []string{"read"} }, nil},
+		{"discovery hosts", func(c *Config) {
+			c.Permissions.Discovery.Prefer = "a.example.com"
+			c.Permissions.Discovery.Fallback = "b.example.com"
+		}, nil},
+		{"discovery interval", func(c *Config) { c.Permissions.Discovery.Interval = time.Minute }, []string{"permissions.discovery.interval"}},
+		{"socket path and sample ratio", func(c *Config) {
+			c.Server.SocketPath = "/tmp/other.sock"
+			c.Tracing.SampleRatio = 0.5
+		}, []string{"server.socketpath", "tracing.sample_ratio"}},
+		{"access token provider", func(c *Config) {
+			c.AccessToken.Enabled = true
+			c.Events.Enabled = true
+		}, []string{"events.enabled"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var current, updated Config
+
+			current.Permissions.Host = "permissions-api.example.com"
+			updated.Permissions.Host = "permissions-api.example.com"
+
+			tc.update(&updated)
+
+			assert.Equal(t, tc.expectChanges, NonReloadableChanges(current, updated), "unexpected changes")
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package degradation
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// ModeFailClosed fails requests with an Unavailable status while permissions-api is unavailable.
+	ModeFailClosed = "fail-closed"
+
+	// ModeStale serves decisions previously made by permissions-api, up to the max age.
+	ModeStale = "stale"
+
+	// ModeAllowlist allows requests for which every action is in the allowlist.
+	ModeAllowlist = "allowlist"
+
+	defaultMaxAge          = 5 * time.Minute
+	defaultCacheMaxEntries = 10000
+)
+
+// Config represents the configuration of the policy deciding requests while permissions-api is unavailable.
+type Config struct {
+	// Mode is the degradation policy: fail-closed, stale or allowlist.
+	// Requests which the policy cannot decide fail closed. Policies other than fail-closed require
+	// the server to prevalidate credentials.
+	// Default: fail-closed
+	Mode string
+
+	// MaxAge is the maximum age of decisions served by the stale policy. Decisions for JWT credentials
+	// are never served beyond the credential's expiry.
+	// Default: 5m
+	MaxAge time.Duration
+
+	// CacheMaxEntries limits the number of action decisions kept for the stale policy.
+	// Default: 10000
+	CacheMaxEntries int
+
+	// Allowlist lists the actions allowed by the allowlist policy.
+	Allowlist []string
+}
+
+// AddFlags sets the command line flags for the degradation policy.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String("degradation.mode", ModeFailClosed, "policy deciding requests while permissions-api is unavailable: fail-closed, stale or allowlist")
+	flags.Duration("degradation.maxage", defaultMaxAge, "maximum age of decisions served by the stale policy")
+	flags.Int("degradation.cachemaxentries", defaultCacheMaxEntries, "maximum number of action decisions kept for the stale policy")
+	flags.StringSlice("degradation.allowlist", nil, "actions allowed by the allowlist policy")
+}
@@ -0,0 +1,2 @@
+// Package degradation decides authorization requests while permissions-api is unavailable.
+package degradation
@@ -0,0 +1,14 @@
+package degradation
+
+import "errors"
+
+var (
+	// ErrInvalidMode is returned when the degradation mode is not a supported mode.
+	ErrInvalidMode = errors.New("invalid degradation mode")
+
+	// ErrInvalidMaxAge is returned when the stale mode is configured without a positive max age.
+	ErrInvalidMaxAge = errors.New("degradation max age must be positive")
+
+	// ErrAllowlistEmpty is returned when the allowlist mode is configured without any actions.
+	ErrAllowlistEmpty = errors.New("degradation allowlist mode requires at least one action")
+)
@@ -0,0 +1,160 @@
+package degradation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+)
+
+// Policy decides requests while permissions-api is unavailable.
+type Policy interface {
+	// Mode returns the degradation mode of the policy.
+	Mode() string
+
+	// Record records the decisions permissions-api made for the actions requested with the credential.
+	Record(credential string, actions []permissions.RequestAction, allowed []bool)
+
+	// Decide returns a decision for every action. False is returned when the policy cannot decide
+	// every action and the request must fail closed.
+	Decide(credential string, actions []permissions.RequestAction) (Decision, bool)
+}
+
+// Decision is a decision made by a degradation policy.
+type Decision struct {
+	// Allowed is whether each requested action is allowed, in the order of the requested actions.
+	Allowed []bool
+
+	// Age is the age of the oldest decision served by the stale policy.
+	Age time.Duration
+}
+
+// NewPolicy creates the degradation policy configured by the config.
+func NewPolicy(config Config) (Policy, error) {
+	switch config.Mode {
+	case "", ModeFailClosed:
+		return failClosedPolicy{}, nil
+	case ModeStale:
+		if config.MaxAge <= 0 {
+			return nil, ErrInvalidMaxAge
+		}
+
+		maxEntries := config.CacheMaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultCacheMaxEntries
+		}
+
+		return &stalePolicy{
+			maxAge: config.MaxAge,
+			cache:  permissions.NewStaleCache(maxEntries),
+			now:    time.Now,
+		}, nil
+	case ModeAllowlist:
+		if len(config.Allowlist) == 0 {
+			return nil, ErrAllowlistEmpty
+		}
+
+		return allowlistPolicy{
+			actions: slices.Clone(config.Allowlist),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMode, config.Mode)
+	}
+}
+
+// failClosedPolicy never decides requests.
+type failClosedPolicy struct{}
+
+func (failClosedPolicy) Mode() string {
+	return ModeFailClosed
+}
+
+func (failClosedPolicy) Record(string, []permissions.RequestAction, []bool) {}
+
+func (failClosedPolicy) Decide(string, []permissions.RequestAction) (Decision, bool) {
+	return Decision{}, false
+}
+
+// allowlistPolicy allows requests for which every action is in the allowlist.
+type allowlistPolicy struct {
+	actions []string
+}
+
+func (allowlistPolicy) Mode() string {
+	return ModeAllowlist
+}
+
+func (allowlistPolicy) Record(string, []permissions.RequestAction, []bool) {}
+
+func (p allowlistPolicy) Decide(_ string, actions []permissions.RequestAction) (Decision, bool) {
+	decision := Decision{
+		Allowed: make([]bool, len(actions)),
+	}
+
+	for i, action := range actions {
+		if !slices.Contains(p.actions, action.Action) {
+			return Decision{}, false
+		}
+
+		decision.Allowed[i] = true
+	}
+
+	return decision, true
+}
+
+// stalePolicy serves decisions previously made by permissions-api, up to the max age.
+// Decisions are kept in a least recently used cache keyed by a hash of the credential, action
+// and resource.
+type stalePolicy struct {
+	maxAge time.Duration
+	cache  *permissions.StaleCache
+
+	now func() time.Time
+}
+
+func (p *stalePolicy) Mode() string {
+	return ModeStale
+}
+
+func (p *stalePolicy) Record(credential string, actions []permissions.RequestAction, allowed []bool) {
+	now := p.now()
+
+	expiresAt := now.Add(p.maxAge)
+
+	if exp, ok := credentialExpiry(credential); ok && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+
+	p.cache.Store(credential, actions, allowed, now, expiresAt)
+}
+
+func (p *stalePolicy) Decide(credential string, actions []permissions.RequestAction) (Decision, bool) {
+	allowed, age, ok := p.cache.Load(credential, actions, p.now())
+	if !ok {
+		return Decision{}, false
+	}
+
+	return Decision{
+		Allowed: allowed,
+		Age:     age,
+	}, true
+}
+
+// credentialExpiry returns the expiry of JWT credentials. The credential is not verified, as it only
+// shortens how long decisions are kept.
+func credentialExpiry(credential string) (time.Time, bool) {
+	token, _, err := gojwt.NewParser().ParseUnverified(credential, gojwt.MapClaims{})
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	exp, err := token.Claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, false
+	}
+
+	return exp.Time, true
+}
@@ -0,0 +1,150 @@
+package degradation
+
+import (
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+)
+
+var (
+	actionGet    = permissions.RequestAction{Action: "loadbalancer_get", ResourceID: "loadbal-abc"}
+	actionUpdate = permissions.RequestAction{Action: "loadbalancer_update", ResourceID: "loadbal-abc"}
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		config      Config
+		expectMode  string
+		expectError error
+	}{
+		{"default", Config{}, ModeFailClosed, nil},
+		{"fail closed", Config{Mode: ModeFailClosed}, ModeFailClosed, nil},
+		{"stale", Config{Mode: ModeStale, MaxAge: time.Minute}, ModeStale, nil},
+		{"stale without max age", Config{Mode: ModeStale}, "", ErrInvalidMaxAge},
+		{"allowlist", Config{Mode: ModeAllowlist, Allowlist: []string{"loadbalancer_get"}}, ModeAllowlist, nil},
+		{"allowlist without actions", Config{Mode: ModeAllowlist}, "", ErrAllowlistEmpty},
+		{"invalid", Config{Mode: "fail-open"}, "", ErrInvalidMode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPolicy(tc.config)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			assert.Equal(t, tc.expectMode, policy.Mode(), "unexpected mode")
+		})
+	}
+}
+
+func TestFailClosedPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewPolicy(Config{})
+	require.NoError(t, err, "no error expected creating policy")
+
+	policy.Record("token", []permissions.RequestAction{actionGet}, []bool{true})
+
+	_, ok := policy.Decide("token", []permissions.RequestAction{actionGet})
+	assert.False(t, ok, "expected request to fail closed")
+}
+
+func TestAllowlistPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewPolicy(Config{Mode: ModeAllowlist, Allowlist: []string{"loadbalancer_get"}})
+	require.NoError(t, err, "no error expected creating policy")
+
+	decision, ok := policy.Decide("token", []permissions.RequestAction{actionGet})
+	require.True(t, ok, "expected allowlisted action to be decided")
+	assert.Equal(t, []bool{true}, decision.Allowed, "expected allowlisted action to be allowed")
+
+	_, ok = policy.Decide("token", []permissions.RequestAction{actionGet, actionUpdate})
+	assert.False(t, ok, "expected request with an action not in the allowlist to fail closed")
+}
+
+func TestStalePolicy(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	policy, err := NewPolicy(Config{Mode: ModeStale, MaxAge: time.Minute, CacheMaxEntries: 3})
+	require.NoError(t, err, "no error expected creating policy")
+
+	stale := policy.(*stalePolicy)
+	stale.now = func() time.Time { return now }
+
+	expiring, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"sub": "idntusr-abc",
+		"exp": now.Add(30 * time.Second).Unix(),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err, "no error expected signing token")
+
+	policy.Record("token", []permissions.RequestAction{actionGet, actionUpdate}, []bool{true, false})
+	policy.Record(expiring, []permissions.RequestAction{actionGet}, []bool{true})
+
+	_, ok := policy.Decide("other-token", []permissions.RequestAction{actionGet})
+	assert.False(t, ok, "expected decisions to not be shared between credentials")
+
+	stale.now = func() time.Time { return now.Add(20 * time.Second) }
+
+	decision, ok := policy.Decide("token", []permissions.RequestAction{actionGet, actionUpdate})
+	require.True(t, ok, "expected recorded decisions to be served")
+	assert.Equal(t, []bool{true, false}, decision.Allowed, "unexpected decisions")
+	assert.Equal(t, 20*time.Second, decision.Age, "unexpected decision age")
+
+	_, ok = policy.Decide(expiring, []permissions.RequestAction{actionGet})
+	assert.True(t, ok, "expected decision to be served before the credential expires")
+
+	stale.now = func() time.Time { return now.Add(40 * time.Second) }
+
+	_, ok = policy.Decide(expiring, []permissions.RequestAction{actionGet})
+	assert.False(t, ok, "expected decision to not be served after the credential expires")
+
+	_, ok = policy.Decide("token", []permissions.RequestAction{actionGet})
+	assert.True(t, ok, "expected decision to be served within the max age")
+
+	stale.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+	_, ok = policy.Decide("token", []permissions.RequestAction{actionGet})
+	assert.False(t, ok, "expected decision to not be served beyond the max age")
+}
+
+func TestStalePolicyEviction(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewPolicy(Config{Mode: ModeStale, MaxAge: time.Minute, CacheMaxEntries: 2})
+	require.NoError(t, err, "no error expected creating policy")
+
+	policy.Record("token", []permissions.RequestAction{actionGet, actionUpdate}, []bool{true, false})
+
+	_, ok := policy.Decide("token", []permissions.RequestAction{actionGet})
+	require.True(t, ok, "expected recorded decision to be served")
+
+	// The cache is full, so the least recently used decision is evicted.
+	policy.Record("new-token", []permissions.RequestAction{actionGet}, []bool{true})
+
+	_, ok = policy.Decide("new-token", []permissions.RequestAction{actionGet})
+	assert.True(t, ok, "expected new decision to be recorded when the cache is full")
+
+	_, ok = policy.Decide("token", []permissions.RequestAction{actionUpdate})
+	assert.False(t, ok, "expected least recently used decision to be evicted")
+
+	_, ok = policy.Decide("token", []permissions.RequestAction{actionGet})
+	assert.True(t, ok, "expected recently used decision to be kept")
+}
@@ -9,6 +9,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const tracerName = "go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
@@ -20,10 +21,22 @@ type Publisher interface {
 	// PublishAuthRelationship is similar to events.Publisher.PublishAuthRelationship, but with no topic.
 	PublishAuthRelationshipRequest(ctx context.Context, message events.AuthRelationshipRequest) (events.Message[events.AuthRelationshipResponse], error)
 
+	// Publish broadcasts the data on the subject.
+	Publish(ctx context.Context, subject string, data []byte) error
+
+	// Subscribe calls the handler for every message broadcast on the subject until the context is canceled.
+	Subscribe(ctx context.Context, subject string, handler MessageHandler) error
+
 	// HealthCheck returns nil when the service is healthy.
 	HealthCheck(ctx context.Context) error
+
+	// Close drains the subscriptions and pending messages and closes the connection.
+	Close(ctx context.Context) error
 }
 
+// MessageHandler handles the data of a received message.
+type MessageHandler func(ctx context.Context, data []byte)
+
 type publisher struct {
 	enabled  bool
 	topic    string
@@ -38,6 +51,91 @@ func (p publisher) PublishAuthRelationshipRequest(ctx context.Context, message e
 	return p.innerPub.PublishAuthRelationshipRequest(ctx, p.topic, message)
 }
 
+// Publish broadcasts the data on the subject using the events connection.
+func (p publisher) Publish(ctx context.Context, subject string, data []byte) error {
+	_, span := tracer.Start(ctx, "Publish", trace.WithAttributes(attribute.String("messaging.destination", subject)))
+	defer span.End()
+
+	if !p.enabled {
+		return ErrPublishNotEnabled
+	}
+
+	conn := p.innerPub.(*events.NATSConnection).Source().(*nats.Conn)
+
+	if err := conn.Publish(subject, data); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return fmt.Errorf("failed to publish to %s: %w", subject, err)
+	}
+
+	return nil
+}
+
+// Subscribe calls the handler for every message broadcast on the subject until the context is canceled.
+// Unlike events subscriptions, no queue group is used so every runtime instance receives every message.
+func (p publisher) Subscribe(ctx context.Context, subject string, handler MessageHandler) error {
+	if !p.enabled {
+		return ErrSubscribeNotEnabled
+	}
+
+	conn := p.innerPub.(*events.NATSConnection).Source().(*nats.Conn)
+
+	sub, err := conn.Subscribe(subject, func(msg *nats.Msg) {
+		handler(ctx, msg.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", subject, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		_ = sub.Unsubscribe() //nolint:errcheck // error check not needed
+	}()
+
+	return nil
+}
+
+// Close drains the subscriptions and pending messages and closes the connection.
+// The connection is closed immediately if draining does not complete before the context is done.
+func (p publisher) Close(ctx context.Context) error {
+	if !p.enabled {
+		return nil
+	}
+
+	conn := p.innerPub.(*events.NATSConnection).Source().(*nats.Conn)
+
+	if conn.IsClosed() {
+		return nil
+	}
+
+	closed := make(chan struct{})
+	closedCB := conn.Opts.ClosedCB
+
+	conn.SetClosedHandler(func(c *nats.Conn) {
+		defer close(closed)
+
+		if closedCB != nil {
+			closedCB(c)
+		}
+	})
+
+	if err := conn.Drain(); err != nil {
+		conn.Close()
+
+		return fmt.Errorf("failed to drain connection: %w", err)
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		conn.Close()
+
+		return fmt.Errorf("failed to drain connection: %w", ctx.Err())
+	}
+}
+
 // HealthCheck returns nil when the service is healthy.
 func (p publisher) HealthCheck(ctx context.Context) error {
 	_, span := tracer.Start(ctx, "HealthCheck")
@@ -0,0 +1,72 @@
+package introspection
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// cacheEntry holds an active introspection result until the token expires.
+type cacheEntry struct {
+	subject   string
+	claims    map[string]any
+	expiresAt time.Time
+}
+
+// resultCache caches active introspection results keyed by a hash of the token.
+type resultCache struct {
+	mu         sync.Mutex
+	entries    map[[32]byte]cacheEntry
+	maxEntries int
+}
+
+func newResultCache(maxEntries int) *resultCache {
+	return &resultCache{
+		entries:    make(map[[32]byte]cacheEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+func hashToken(token string) [32]byte {
+	return sha256.Sum256([]byte(token))
+}
+
+// get returns the cached result for the token if it has not expired.
+func (c *resultCache) get(key [32]byte, now time.Time) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+// set caches the result, pruning expired entries when the cache is full.
+// If the cache is still full after pruning, the result is not cached.
+func (c *resultCache) set(key [32]byte, entry cacheEntry, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+
+	c.entries[key] = entry
+}
@@ -0,0 +1,71 @@
+package introspection
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultTimeout         = 5 * time.Second
+	defaultCacheMaxEntries = 10000
+)
+
+// Config represents the configuration for token introspection.
+type Config struct {
+	// Enabled enables introspection of credentials which are not JWTs.
+	Enabled bool
+
+	// Issuer specifies the URL for the issuer of opaque tokens.
+	// The introspection endpoint is discovered from the issuer's .well-known/openid-configuration.
+	Issuer string
+
+	// ClientID is the client id used to authenticate to the introspection endpoint.
+	// May be a file path by using the file:// scheme.
+	ClientID string
+
+	// ClientSecret is the client secret used to authenticate to the introspection endpoint.
+	// May be a file path by using the file:// scheme.
+	ClientSecret string
+
+	// Timeout sets the timeout for introspection requests.
+	//
+	// Default: 5s
+	Timeout time.Duration
+
+	// CacheMaxEntries limits the number of introspection results cached until their expiry.
+	//
+	// Default: 10000
+	CacheMaxEntries int
+}
+
+// Validate ensures the config has been configured properly.
+func (c Config) Validate() error {
+	if c.Issuer == "" {
+		return ErrIssuerRequired
+	}
+
+	return nil
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = defaultTimeout
+	}
+
+	if c.CacheMaxEntries <= 0 {
+		c.CacheMaxEntries = defaultCacheMaxEntries
+	}
+
+	return c
+}
+
+// AddFlags sets the command line flags for token introspection.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool("introspection.enabled", false, "enable introspection of opaque (non-JWT) credentials")
+	flags.String("introspection.issuer", "", "issuer to discover the introspection endpoint from")
+	flags.String("introspection.clientid", "", "client id used to authenticate to the introspection endpoint, may be a file:// path")
+	flags.String("introspection.clientsecret", "", "client secret used to authenticate to the introspection endpoint, may be a file:// path")
+	flags.Duration("introspection.timeout", defaultTimeout, "timeout for introspection requests")
+	flags.Int("introspection.cachemaxentries", defaultCacheMaxEntries, "maximum number of introspection results cached until expiry")
+}
@@ -0,0 +1,2 @@
+// Package introspection validates opaque access tokens using OAuth 2.0 token introspection (RFC 7662).
+package introspection
@@ -0,0 +1,26 @@
+package introspection
+
+import "errors"
+
+var (
+	// ErrServiceDisabled is returned when calling a service method while the service is disabled.
+	ErrServiceDisabled = errors.New("introspection service disabled")
+
+	// ErrIssuerRequired is returned when no Issuer has been configured.
+	ErrIssuerRequired = errors.New("issuer is required")
+
+	// ErrIntrospectionEndpointMissing is returned when the issuer metadata is missing the introspection_endpoint key.
+	ErrIntrospectionEndpointMissing = errors.New("introspection endpoint missing from issuer well-known openid-configuration")
+
+	// ErrIntrospectionFailed is returned when the introspection endpoint could not be queried.
+	ErrIntrospectionFailed = errors.New("token introspection request failed")
+
+	// ErrTokenInactive is returned when the introspection endpoint reports the token as inactive.
+	ErrTokenInactive = errors.New("token inactive")
+
+	// ErrSubjectMissing is returned when an active token has no sub.
+	ErrSubjectMissing = errors.New("introspection response missing sub")
+
+	// ErrIssuerMismatch is returned when the introspection response iss does not match the configured issuer.
+	ErrIssuerMismatch = errors.New("introspection response issuer mismatch")
+)
@@ -0,0 +1,219 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+)
+
+const tracerName = "go.infratographer.com/iam-runtime-infratographer/internal/introspection"
+
+var tracer = otel.GetTracerProvider().Tracer(tracerName)
+
+// Introspector validates opaque tokens with the issuer's introspection endpoint.
+type Introspector interface {
+	// Introspect checks that the given token is active. On success, it returns the subject
+	// and the claims from the introspection response.
+	Introspect(ctx context.Context, token string) (string, map[string]any, error)
+}
+
+type introspector struct {
+	enabled bool
+
+	issuer       string
+	endpoint     string
+	clientID     string
+	clientSecret string
+
+	httpClient *http.Client
+	cache      *resultCache
+	now        func() time.Time
+}
+
+// NewIntrospector creates an introspector with the given configuration.
+// The introspection endpoint is discovered from the configured issuer.
+func NewIntrospector(ctx context.Context, config Config) (Introspector, error) {
+	if !config.Enabled {
+		return &introspector{
+			enabled: false,
+		}, nil
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	config = config.withDefaults()
+
+	metadata, err := jwt.FetchIssuerMetadata(ctx, config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issuer metadata: %w", err)
+	}
+
+	if metadata.IntrospectionEndpoint == "" {
+		return nil, ErrIntrospectionEndpointMissing
+	}
+
+	clientID, err := accesstoken.ReadSecret(config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientSecret, err := accesstoken.ReadSecret(config.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &introspector{
+		enabled: true,
+
+		issuer:       config.Issuer,
+		endpoint:     metadata.IntrospectionEndpoint,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+		},
+		cache: newResultCache(config.CacheMaxEntries),
+		now:   time.Now,
+	}
+
+	return out, nil
+}
+
+// Introspect checks that the given token is active. Active results are cached until the token expires.
+func (i *introspector) Introspect(ctx context.Context, token string) (string, map[string]any, error) {
+	if !i.enabled {
+		return "", nil, ErrServiceDisabled
+	}
+
+	ctx, span := tracer.Start(ctx, "Introspect")
+	defer span.End()
+
+	key := hashToken(token)
+	now := i.now()
+
+	if entry, ok := i.cache.get(key, now); ok {
+		span.SetAttributes(attribute.Bool("introspection.cache.hit", true))
+
+		// Callers may modify the returned claims, so the cached map is never shared.
+		return entry.subject, maps.Clone(entry.claims), nil
+	}
+
+	span.SetAttributes(attribute.Bool("introspection.cache.hit", false))
+
+	claims, err := i.request(ctx, token)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return "", nil, err
+	}
+
+	sub, exp, err := i.checkClaims(claims, now)
+	if err != nil {
+		span.SetAttributes(attribute.String("introspection.outcome", "inactive"))
+
+		return "", nil, err
+	}
+
+	span.SetAttributes(attribute.String("introspection.outcome", "active"))
+
+	if !exp.IsZero() {
+		i.cache.set(key, cacheEntry{subject: sub, claims: maps.Clone(claims), expiresAt: exp}, now)
+	}
+
+	return sub, claims, nil
+}
+
+// request calls the introspection endpoint, returning the response claims.
+func (i *introspector) request(ctx context.Context, token string) (map[string]any, error) {
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {"access_token"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospectionFailed, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if i.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+	}
+
+	res, err := i.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospectionFailed, err)
+	}
+	defer res.Body.Close() //nolint:errcheck // no need to check
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status code %d", ErrIntrospectionFailed, res.StatusCode)
+	}
+
+	claims := map[string]any{}
+
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrIntrospectionFailed, err)
+	}
+
+	return claims, nil
+}
+
+// checkClaims ensures the token is active, returning the subject and expiry.
+// The active flag is removed from the claims.
+func (i *introspector) checkClaims(claims map[string]any, now time.Time) (string, time.Time, error) {
+	active, _ := claims["active"].(bool)
+	delete(claims, "active")
+
+	if !active {
+		return "", time.Time{}, ErrTokenInactive
+	}
+
+	if iss, ok := claims["iss"].(string); ok && strings.TrimRight(iss, "/") != strings.TrimRight(i.issuer, "/") {
+		return "", time.Time{}, fmt.Errorf("%w: %q", ErrIssuerMismatch, iss)
+	}
+
+	var exp time.Time
+
+	if n, ok := claims["exp"].(json.Number); ok {
+		seconds, err := n.Int64()
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("%w: invalid exp: %w", ErrTokenInactive, err)
+		}
+
+		exp = time.Unix(seconds, 0)
+
+		if !now.Before(exp) {
+			return "", time.Time{}, fmt.Errorf("%w: token expired", ErrTokenInactive)
+		}
+	}
+
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return "", time.Time{}, ErrSubjectMissing
+	}
+
+	return sub, exp, nil
+}
@@ -0,0 +1,204 @@
+package introspection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIssuer struct {
+	*httptest.Server
+
+	requests atomic.Int32
+	response func(issuer, token string) (int, map[string]any)
+}
+
+func newTestIssuer(t *testing.T, response func(issuer, token string) (int, map[string]any)) *testIssuer {
+	t.Helper()
+
+	issuer := &testIssuer{
+		response: response,
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL,
+			"introspection_endpoint": issuer.URL + "/introspect",
+		})
+	})
+
+	mux.HandleFunc("/introspect", func(w http.ResponseWriter, r *http.Request) {
+		issuer.requests.Add(1)
+
+		if user, pass, ok := r.BasicAuth(); !ok || user != "client-id" || pass != "client-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		status, body := issuer.response(issuer.URL, r.PostFormValue("token"))
+
+		w.WriteHeader(status)
+
+		_ = json.NewEncoder(w).Encode(body)
+	})
+
+	issuer.Server = httptest.NewServer(mux)
+
+	t.Cleanup(issuer.Close)
+
+	return issuer
+}
+
+func TestIntrospect(t *testing.T) {
+	t.Parallel()
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	responses := map[string]map[string]any{
+		"active":         {"active": true, "sub": "idntusr-abc", "scope": "read", "exp": exp},
+		"active-no-exp":  {"active": true, "sub": "idntusr-abc"},
+		"inactive":       {"active": false},
+		"expired":        {"active": true, "sub": "idntusr-abc", "exp": time.Now().Add(-time.Minute).Unix()},
+		"missing-sub":    {"active": true, "exp": exp},
+		"other-issuer":   {"active": true, "sub": "idntusr-abc", "iss": "https://other.example.com", "exp": exp},
+		"server-failure": nil,
+	}
+
+	issuer := newTestIssuer(t, func(_, token string) (int, map[string]any) {
+		body := responses[token]
+		if body == nil {
+			return http.StatusInternalServerError, map[string]any{}
+		}
+
+		return http.StatusOK, body
+	})
+
+	i, err := NewIntrospector(context.Background(), Config{
+		Enabled:      true,
+		Issuer:       issuer.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+	})
+	require.NoError(t, err, "no error expected creating introspector")
+
+	testCases := []struct {
+		name        string
+		token       string
+		expectError error
+		expectCache bool
+	}{
+		{"active", "active", nil, true},
+		{"active without expiry", "active-no-exp", nil, false},
+		{"inactive", "inactive", ErrTokenInactive, false},
+		{"expired", "expired", ErrTokenInactive, false},
+		{"missing subject", "missing-sub", ErrSubjectMissing, false},
+		{"issuer mismatch", "other-issuer", ErrIssuerMismatch, false},
+		{"server failure", "server-failure", ErrIntrospectionFailed, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, claims, err := i.Introspect(context.Background(), tc.token)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			assert.Equal(t, "idntusr-abc", sub, "unexpected subject")
+			assert.NotContains(t, claims, "active", "expected active to be removed from claims")
+
+			before := issuer.requests.Load()
+
+			_, _, err = i.Introspect(context.Background(), tc.token)
+			require.NoError(t, err, "no error expected")
+
+			if tc.expectCache {
+				assert.Equal(t, before, issuer.requests.Load(), "expected result to be cached")
+			} else {
+				assert.Equal(t, before+1, issuer.requests.Load(), "expected result to not be cached")
+			}
+		})
+	}
+}
+
+func TestIntrospectCacheExpires(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	issuer := newTestIssuer(t, func(_, _ string) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"active": true, "sub": "idntusr-abc", "exp": now.Add(time.Minute).Unix()}
+	})
+
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("client-secret\n"), 0o600), "no error expected writing secret")
+
+	i, err := NewIntrospector(context.Background(), Config{
+		Enabled:      true,
+		Issuer:       issuer.URL,
+		ClientID:     "client-id",
+		ClientSecret: fmt.Sprintf("file://%s/secret", dir),
+	})
+	require.NoError(t, err, "no error expected creating introspector")
+
+	i.(*introspector).now = func() time.Time { return now }
+
+	_, claims, err := i.Introspect(context.Background(), "token")
+	require.NoError(t, err, "no error expected")
+
+	claims["sub"] = "modified"
+
+	_, claims, err = i.Introspect(context.Background(), "token")
+	require.NoError(t, err, "no error expected")
+
+	assert.Equal(t, int32(1), issuer.requests.Load(), "expected cached result to be used")
+	assert.Equal(t, "idntusr-abc", claims["sub"], "expected cached claims to not be shared between callers")
+
+	i.(*introspector).now = func() time.Time { return now.Add(2 * time.Minute) }
+
+	_, _, err = i.Introspect(context.Background(), "token")
+	assert.ErrorIs(t, err, ErrTokenInactive, "expected token to be expired")
+
+	assert.Equal(t, int32(2), issuer.requests.Load(), "expected expired result to be introspected again")
+}
+
+func TestIntrospectorDisabled(t *testing.T) {
+	t.Parallel()
+
+	i, err := NewIntrospector(context.Background(), Config{})
+	require.NoError(t, err, "no error expected creating introspector")
+
+	_, _, err = i.Introspect(context.Background(), "token")
+
+	assert.ErrorIs(t, err, ErrServiceDisabled, "expected service disabled error")
+}
+
+func TestNewIntrospectorEndpointMissing(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"token_endpoint":"https://issuer.example.com/token"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := NewIntrospector(context.Background(), Config{Enabled: true, Issuer: srv.URL})
+
+	assert.ErrorIs(t, err, ErrIntrospectionEndpointMissing, "expected endpoint missing error")
+}
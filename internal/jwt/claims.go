@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrRequiredClaimMissing is returned when a token is missing a claim configured as required.
+	ErrRequiredClaimMissing = fmt.Errorf("%w: required claim missing", jwt.ErrTokenInvalidClaims)
+
+	// ErrRequiredClaimMismatch is returned when a required claim does not contain the configured value.
+	ErrRequiredClaimMismatch = fmt.Errorf("%w: required claim value mismatch", jwt.ErrTokenInvalidClaims)
+)
+
+// validateAudience ensures the token audience contains at least one of the expected audiences.
+// If no audiences are expected, any audience is accepted.
+func validateAudience(claims jwt.MapClaims, expected []string) error {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	aud, err := claims.GetAudience()
+	if err != nil {
+		return err
+	}
+
+	if len(aud) == 0 {
+		return fmt.Errorf("%w: %w", jwt.ErrTokenRequiredClaimMissing, jwt.ErrTokenInvalidAudience)
+	}
+
+	// Compare every pair to keep the comparison constant time.
+	var found bool
+
+	for _, exp := range expected {
+		for _, a := range aud {
+			if subtle.ConstantTimeCompare([]byte(a), []byte(exp)) == 1 {
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return jwt.ErrTokenInvalidAudience
+	}
+
+	return nil
+}
+
+// validateRequiredClaims ensures each required claim exists on the token.
+// If a required claim has a non-empty value, every whitespace separated value must be contained by the claim.
+// String claims are matched exactly or by their whitespace separated values (for example an OAuth scope),
+// array claims are matched by their elements and all other claims are matched by their string representation.
+func validateRequiredClaims(claims jwt.MapClaims, required map[string]string) error {
+	var errs []error
+
+	for name, value := range required {
+		claim, ok := claims[name]
+		if !ok || claim == nil {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrRequiredClaimMissing, name))
+
+			continue
+		}
+
+		values := claimValues(claim)
+
+		for _, want := range strings.Fields(value) {
+			if _, ok := values[want]; !ok {
+				errs = append(errs, fmt.Errorf("%w: %s does not contain %q", ErrRequiredClaimMismatch, name, want))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// claimValues returns the set of values a claim may be matched against.
+func claimValues(claim any) map[string]struct{} {
+	values := make(map[string]struct{})
+
+	switch v := claim.(type) {
+	case string:
+		values[v] = struct{}{}
+
+		for _, field := range strings.Fields(v) {
+			values[field] = struct{}{}
+		}
+	case []any:
+		for _, elem := range v {
+			values[fmt.Sprint(elem)] = struct{}{}
+		}
+	case []string:
+		for _, elem := range v {
+			values[elem] = struct{}{}
+		}
+	default:
+		values[fmt.Sprint(v)] = struct{}{}
+	}
+
+	return values
+}
@@ -12,12 +12,104 @@ type Config struct {
 	Issuer              string
 	JWKSURI             string
 	JWKSRefreshInterval time.Duration
+
+	// JWKSFile is the path to a local JWKS file, such as a mounted Kubernetes secret.
+	// The file is watched for changes and its keys are merged with the keys from JWKSURI.
+	// When set without JWKSURI, the JWKS URI is not discovered from the issuer.
+	JWKSFile string
+
+	// DiscoveryInterval sets the interval at which the issuer metadata is re-discovered
+	// when the JWKS URI is discovered from the issuer. A zero value disables re-discovery.
+	//
+	// Default: 1h
+	DiscoveryInterval time.Duration
+
+	// Issuers lists additional trusted issuers, each with their own JWKS.
+	// The issuer used to validate a token is selected by the token's iss claim.
+	// When set, every issuer, including the top level issuer, must be named. A single
+	// top level issuer without a name accepts tokens from any issuer.
+	Issuers []IssuerConfig
+
+	// Audiences lists the accepted audiences.
+	// When defined, a token must contain at least one of these audiences.
+	Audiences []string
+
+	// Leeway allows for clock skew when validating the exp, nbf and iat claims.
+	Leeway time.Duration
+
+	// Algorithms restricts the accepted token signing algorithms.
+	// When empty, any algorithm supported by the signing key is accepted.
+	Algorithms []string
+
+	// RequiredClaims maps claim names to a value the claim must contain.
+	// An empty value only requires the claim to be present.
+	// Multiple values may be required by separating them with spaces.
+	RequiredClaims map[string]string
+}
+
+// IssuerConfig represents the configuration for a trusted token issuer.
+type IssuerConfig struct {
+	// Issuer is the expected iss claim of tokens issued by this issuer.
+	Issuer string
+
+	// JWKSURI is the JWKS URI used to fetch the issuer's signing keys.
+	// When empty, the JWKS URI is discovered from the issuer's .well-known/openid-configuration.
+	JWKSURI string
+
+	// JWKSFile is the path to a local JWKS file, merged with the keys from JWKSURI.
+	// When set without JWKSURI, the JWKS URI is not discovered from the issuer.
+	JWKSFile string
+
+	// JWKSRefreshInterval sets the interval at which the JWKS is refreshed.
+	//
+	// Default: [Config] JWKSRefreshInterval
+	JWKSRefreshInterval time.Duration
+
+	// DiscoveryInterval sets the interval at which the JWKS URI is re-discovered.
+	//
+	// Default: [Config] DiscoveryInterval
+	DiscoveryInterval time.Duration
+}
+
+// issuers returns all trusted issuers, including the top level issuer if configured.
+func (c Config) issuers() []IssuerConfig {
+	issuers := make([]IssuerConfig, 0, len(c.Issuers)+1)
+
+	if c.Issuer != "" || c.JWKSURI != "" || c.JWKSFile != "" {
+		issuers = append(issuers, IssuerConfig{
+			Issuer:              c.Issuer,
+			JWKSURI:             c.JWKSURI,
+			JWKSFile:            c.JWKSFile,
+			JWKSRefreshInterval: c.JWKSRefreshInterval,
+			DiscoveryInterval:   c.DiscoveryInterval,
+		})
+	}
+
+	for _, issuer := range c.Issuers {
+		if issuer.JWKSRefreshInterval == 0 {
+			issuer.JWKSRefreshInterval = c.JWKSRefreshInterval
+		}
+
+		if issuer.DiscoveryInterval == 0 {
+			issuer.DiscoveryInterval = c.DiscoveryInterval
+		}
+
+		issuers = append(issuers, issuer)
+	}
+
+	return issuers
 }
 
 // AddFlags sets the command line flags for JWT validation.
 func AddFlags(flags *pflag.FlagSet) {
 	flags.Bool("jwt.disable", false, "Disable JWT service")
 	flags.String("jwt.issuer", "", "Issuer to use for JWT validation")
-	flags.String("jwt.jwksuri", "", "JWKS URI to use for JWT validation")
+	flags.String("jwt.jwksuri", "", "JWKS URI to use for JWT validation (default: discovered from the issuer)")
+	flags.String("jwt.jwksfile", "", "path to a local JWKS file to use for JWT validation, merged with keys from the JWKS URI")
 	flags.Duration("jwt.jwksrefreshinterval", time.Hour, "sets the jwks refresh interval")
+	flags.Duration("jwt.discoveryinterval", time.Hour, "sets the interval the jwks uri is re-discovered from the issuer when jwksuri is not set")
+	flags.StringSlice("jwt.audiences", []string{}, "audiences accepted for JWT validation, a token must contain at least one")
+	flags.Duration("jwt.leeway", 0, "leeway allowed for clock skew when validating time based claims")
+	flags.StringSlice("jwt.algorithms", []string{}, "signing algorithms accepted for JWT validation (default: any supported by the key)")
+	flags.StringToString("jwt.requiredclaims", map[string]string{}, "claims required to be present, optionally with values the claim must contain (e.g. scope=read)")
 }
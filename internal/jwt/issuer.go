@@ -0,0 +1,279 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/MicahParks/jwkset"
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+)
+
+// discoveryTimeout is the maximum time allowed to fetch the issuer metadata.
+const discoveryTimeout = 30 * time.Second
+
+// issuer holds the key storage and parser for a single trusted issuer.
+type issuer struct {
+	name   string
+	parser *jwt.Parser
+	client *http.Client
+
+	refreshInterval time.Duration
+
+	file   *jwksFile
+	keys   atomic.Pointer[issuerKeys]
+	done   chan struct{}
+	logger *zap.SugaredLogger
+}
+
+// issuerKeys holds the key storage loaded from a single JWKS URI.
+type issuerKeys struct {
+	jwksURI string
+	kf      jwt.Keyfunc
+	storage jwkset.Storage
+	cancel  context.CancelFunc
+}
+
+func newIssuer(config IssuerConfig, parserOpts []jwt.ParserOption, logger *zap.SugaredLogger) (*issuer, error) {
+	transport := otelhttp.NewTransport(http.DefaultTransport)
+	client := &http.Client{
+		Transport: transport,
+	}
+
+	parserOpts = append(parserOpts, jwt.WithIssuer(config.Issuer))
+
+	out := &issuer{
+		name:   config.Issuer,
+		parser: jwt.NewParser(parserOpts...),
+		client: client,
+
+		refreshInterval: config.JWKSRefreshInterval,
+
+		done:   make(chan struct{}),
+		logger: logger.With("issuer", config.Issuer),
+	}
+
+	if config.JWKSFile != "" {
+		file, err := newJWKSFile(config.JWKSFile, out.logger)
+		if err != nil {
+			return nil, err
+		}
+
+		out.file = file
+	}
+
+	jwksURI := config.JWKSURI
+	discover := jwksURI == "" && out.file == nil
+
+	if discover {
+		uri, err := out.discoverJWKSURI(context.Background())
+		if err != nil {
+			out.close()
+
+			return nil, err
+		}
+
+		jwksURI = uri
+	}
+
+	keys, err := out.loadKeys(jwksURI)
+	if err != nil {
+		out.close()
+
+		return nil, err
+	}
+
+	out.keys.Store(keys)
+
+	if discover && config.DiscoveryInterval > 0 {
+		go out.rediscover(config.DiscoveryInterval)
+	}
+
+	return out, nil
+}
+
+// discoverJWKSURI fetches the JWKS URI from the issuer's well-known openid-configuration.
+func (i *issuer) discoverJWKSURI(ctx context.Context) (string, error) {
+	ctx, span := tracer.Start(ctx, "discoverJWKSURI")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("jwt.issuer", i.name))
+
+	ctx, cancel := context.WithTimeout(ctx, discoveryTimeout)
+	defer cancel()
+
+	uri, err := FetchIssuerJWKSURI(ctx, i.name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return "", fmt.Errorf("failed to discover jwks uri: %w", err)
+	}
+
+	span.SetAttributes(attribute.String("jwt.jwks_uri", uri))
+
+	return uri, nil
+}
+
+// loadKeys creates a new key storage for the provided JWKS URI.
+// When a JWKS file is configured, its keys are merged with the keys from the JWKS URI.
+func (i *issuer) loadKeys(jwksURI string) (*issuerKeys, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	storage, err := i.newStorage(ctx, jwksURI)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	keyfuncOpts := keyfunc.Options{
+		Storage: storage,
+	}
+
+	kf, err := keyfunc.New(keyfuncOpts)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	out := &issuerKeys{
+		jwksURI: jwksURI,
+		kf:      kf.Keyfunc,
+		storage: storage,
+		cancel:  cancel,
+	}
+
+	return out, nil
+}
+
+// newStorage creates the key storage from the JWKS file and JWKS URI.
+func (i *issuer) newStorage(ctx context.Context, jwksURI string) (jwkset.Storage, error) {
+	if jwksURI == "" {
+		return i.file.storage, nil
+	}
+
+	jwksURL, err := url.Parse(jwksURI)
+	if err != nil {
+		return nil, err
+	}
+
+	storageOpts := jwkset.HTTPClientStorageOptions{
+		Client:          i.client,
+		Ctx:             ctx,
+		RefreshInterval: i.refreshInterval,
+		RefreshErrorHandler: func(_ context.Context, err error) {
+			metrics.IncJWKSRefreshFailures(i.name)
+
+			i.logger.Warnw("failed to refresh jwks", "jwks_uri", jwksURI, "error", err)
+		},
+	}
+
+	storage, err := jwkset.NewStorageFromHTTP(jwksURL.String(), storageOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.file == nil {
+		return storage, nil
+	}
+
+	return jwkset.NewHTTPClient(jwkset.HTTPClientOptions{
+		Given: i.file.storage,
+		HTTPURLs: map[string]jwkset.Storage{
+			jwksURL.String(): storage,
+		},
+	})
+}
+
+// rediscover periodically refreshes the issuer metadata, replacing the key storage when the JWKS URI changes.
+func (i *issuer) rediscover(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.done:
+			return
+		case <-ticker.C:
+		}
+
+		if err := i.refreshDiscovery(context.Background()); err != nil {
+			i.logger.Warnw("failed to refresh issuer discovery", "error", err)
+		}
+	}
+}
+
+// refreshDiscovery re-discovers the JWKS URI and swaps the key storage if it has changed.
+func (i *issuer) refreshDiscovery(ctx context.Context) error {
+	uri, err := i.discoverJWKSURI(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := i.keys.Load()
+	if current.jwksURI == uri {
+		return nil
+	}
+
+	keys, err := i.loadKeys(uri)
+	if err != nil {
+		return err
+	}
+
+	if old := i.keys.Swap(keys); old != nil {
+		old.cancel()
+	}
+
+	i.logger.Infow("issuer jwks uri changed", "jwks_uri", uri)
+
+	return nil
+}
+
+// close stops re-discovery, JWKS refreshes and watching the JWKS file.
+func (i *issuer) close() {
+	close(i.done)
+
+	if keys := i.keys.Load(); keys != nil {
+		keys.cancel()
+	}
+
+	if i.file != nil {
+		i.file.close()
+	}
+}
+
+// keyfunc returns the verification key for the token from the current key storage.
+func (i *issuer) keyfunc(token *jwt.Token) (any, error) {
+	return i.keys.Load().kf(token)
+}
+
+// healthCheck ensures the issuer has valid keys loaded.
+func (i *issuer) healthCheck(ctx context.Context) error {
+	keys, err := i.keys.Load().storage.KeyReadAll(ctx)
+	if err != nil {
+		return fmt.Errorf("issuer %s: %w", i.name, err)
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: issuer %s", ErrIssuerKeysMissing, i.name)
+	}
+
+	for _, key := range keys {
+		if err := key.Validate(); err != nil {
+			return fmt.Errorf("issuer %s: %w", i.name, err)
+		}
+	}
+
+	return nil
+}
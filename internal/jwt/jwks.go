@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -20,34 +21,95 @@ var (
 
 	// ErrTokenEndpointMissing is returned when the issuers .well-known/openid-configuration is missing the token_endpoint key.
 	ErrTokenEndpointMissing = errors.New("token endpoint missing from issuer well-known openid-configuration")
+
+	// ErrJWKSURIMissing is returned when the issuers .well-known/openid-configuration is missing the jwks_uri key.
+	ErrJWKSURIMissing = errors.New("jwks uri missing from issuer well-known openid-configuration")
+
+	// ErrIssuerMetadataMismatch is returned when the issuer in the metadata does not match the requested issuer.
+	ErrIssuerMetadataMismatch = errors.New("issuer well-known openid-configuration issuer mismatch")
+
+	// ErrIssuerMetadataRequestFailed is returned when the issuer metadata could not be retrieved.
+	ErrIssuerMetadataRequestFailed = errors.New("issuer well-known openid-configuration request failed")
 )
 
-type jwksTokenEndpoint struct {
+// IssuerMetadata represents the OpenID provider metadata published by an issuer
+// at .well-known/openid-configuration.
+type IssuerMetadata struct {
+	// Issuer is the issuer identifier.
+	Issuer string `json:"issuer"`
+
+	// JWKSURI is the URL of the issuer's JSON Web Key Set.
+	JWKSURI string `json:"jwks_uri"`
+
+	// TokenEndpoint is the URL of the issuer's OAuth 2.0 token endpoint.
 	TokenEndpoint string `json:"token_endpoint"`
+
+	// IntrospectionEndpoint is the URL of the issuer's OAuth 2.0 token introspection endpoint.
+	IntrospectionEndpoint string `json:"introspection_endpoint"`
+
+	// IDTokenSigningAlgValuesSupported lists the signing algorithms supported by the issuer.
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
 }
 
-// FetchIssuerTokenEndpoint returns the token endpoint for the provided issuer.
-func FetchIssuerTokenEndpoint(ctx context.Context, issuer string) (string, error) {
+// FetchIssuerMetadata returns the OpenID provider metadata for the provided issuer.
+// If the metadata includes an issuer, it must match the provided issuer.
+func FetchIssuerMetadata(ctx context.Context, issuer string) (IssuerMetadata, error) {
 	uri, err := url.JoinPath(issuer, ".well-known", "openid-configuration")
 	if err != nil {
-		return "", fmt.Errorf("invalid issuer: %w", err)
+		return IssuerMetadata{}, fmt.Errorf("invalid issuer: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
-		return "", err
+		return IssuerMetadata{}, err
 	}
 
 	res, err := tokenEndpointClient.Do(req)
 	if err != nil {
-		return "", err
+		return IssuerMetadata{}, err
 	}
 	defer res.Body.Close() //nolint:errcheck // no need to check
 
-	var jwks jwksTokenEndpoint
-	if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+	if res.StatusCode != http.StatusOK {
+		return IssuerMetadata{}, fmt.Errorf("%w: status code %d", ErrIssuerMetadataRequestFailed, res.StatusCode)
+	}
+
+	var metadata IssuerMetadata
+	if err := json.NewDecoder(res.Body).Decode(&metadata); err != nil {
+		return IssuerMetadata{}, err
+	}
+
+	if metadata.Issuer != "" && strings.TrimRight(metadata.Issuer, "/") != strings.TrimRight(issuer, "/") {
+		return IssuerMetadata{}, fmt.Errorf("%w: expected %q got %q", ErrIssuerMetadataMismatch, issuer, metadata.Issuer)
+	}
+
+	return metadata, nil
+}
+
+// FetchIssuerTokenEndpoint returns the token endpoint for the provided issuer.
+func FetchIssuerTokenEndpoint(ctx context.Context, issuer string) (string, error) {
+	metadata, err := FetchIssuerMetadata(ctx, issuer)
+	if err != nil {
+		return "", err
+	}
+
+	if metadata.TokenEndpoint == "" {
+		return "", ErrTokenEndpointMissing
+	}
+
+	return metadata.TokenEndpoint, nil
+}
+
+// FetchIssuerJWKSURI returns the JWKS URI for the provided issuer.
+func FetchIssuerJWKSURI(ctx context.Context, issuer string) (string, error) {
+	metadata, err := FetchIssuerMetadata(ctx, issuer)
+	if err != nil {
 		return "", err
 	}
 
-	return jwks.TokenEndpoint, nil
+	if metadata.JWKSURI == "" {
+		return "", ErrJWKSURIMissing
+	}
+
+	return metadata.JWKSURI, nil
 }
@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/testauth"
+)
+
+func TestFetchIssuerMetadata(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	metadata, err := FetchIssuerMetadata(context.Background(), authsrv.Issuer)
+	require.NoError(t, err, "no error expected fetching metadata")
+
+	assert.Equal(t, authsrv.Issuer, metadata.Issuer, "unexpected issuer")
+	assert.Equal(t, authsrv.Issuer+"/.well-known/jwks.json", metadata.JWKSURI, "unexpected jwks uri")
+	assert.Equal(t, authsrv.Issuer+"/token", metadata.TokenEndpoint, "unexpected token endpoint")
+	assert.Equal(t, []string{"RS256"}, metadata.IDTokenSigningAlgValuesSupported, "unexpected signing algorithms")
+}
+
+func TestFetchIssuerMetadataErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		status      int
+		body        string
+		fetch       func(ctx context.Context, issuer string) error
+		expectError error
+	}{
+		{
+			"request failed",
+			http.StatusNotFound,
+			`{}`,
+			func(ctx context.Context, issuer string) error {
+				_, err := FetchIssuerMetadata(ctx, issuer)
+
+				return err
+			},
+			ErrIssuerMetadataRequestFailed,
+		},
+		{
+			"issuer mismatch",
+			http.StatusOK,
+			`{"issuer":"https://other.example.com","jwks_uri":"https://other.example.com/jwks.json"}`,
+			func(ctx context.Context, issuer string) error {
+				_, err := FetchIssuerMetadata(ctx, issuer)
+
+				return err
+			},
+			ErrIssuerMetadataMismatch,
+		},
+		{
+			"jwks uri missing",
+			http.StatusOK,
+			`{"token_endpoint":"https://issuer.example.com/token"}`,
+			func(ctx context.Context, issuer string) error {
+				_, err := FetchIssuerJWKSURI(ctx, issuer)
+
+				return err
+			},
+			ErrJWKSURIMissing,
+		},
+		{
+			"token endpoint missing",
+			http.StatusOK,
+			`{"jwks_uri":"https://issuer.example.com/jwks.json"}`,
+			func(ctx context.Context, issuer string) error {
+				_, err := FetchIssuerTokenEndpoint(ctx, issuer)
+
+				return err
+			},
+			ErrTokenEndpointMissing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			err := tc.fetch(context.Background(), srv.URL)
+
+			assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MicahParks/jwkset"
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// jwksFile holds the keys loaded from a local JWKS file, reloading them when the file changes.
+type jwksFile struct {
+	path    string
+	storage *jwkset.MemoryJWKSet
+	watcher *fsnotify.Watcher
+	logger  *zap.SugaredLogger
+}
+
+// newJWKSFile loads the JWKS file and starts watching it for changes.
+func newJWKSFile(path string, logger *zap.SugaredLogger) (*jwksFile, error) {
+	out := &jwksFile{
+		path:    filepath.Clean(path),
+		storage: jwkset.NewMemoryStorage(),
+		logger:  logger.With("jwks_file", path),
+	}
+
+	if err := out.load(context.Background()); err != nil {
+		return nil, err
+	}
+
+	// The parent directory is watched instead of the file itself so replacements,
+	// such as atomic renames and Kubernetes secret symlink swaps, are detected.
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks file watcher: %w", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(out.path)); err != nil {
+		_ = watcher.Close() //nolint:errcheck // error check not needed
+
+		return nil, fmt.Errorf("failed to watch jwks file: %w", err)
+	}
+
+	out.watcher = watcher
+
+	go out.watch()
+
+	return out, nil
+}
+
+// load reads the JWKS file and replaces all keys in storage.
+func (f *jwksFile) load(ctx context.Context) error {
+	content, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read jwks file %s: %w", f.path, err)
+	}
+
+	var jwks jwkset.JWKSMarshal
+
+	if err := json.Unmarshal(content, &jwks); err != nil {
+		return fmt.Errorf("failed to parse jwks file %s: %w", f.path, err)
+	}
+
+	keys, err := jwks.JWKSlice()
+	if err != nil {
+		return fmt.Errorf("failed to load jwks file %s: %w", f.path, err)
+	}
+
+	return f.storage.KeyReplaceAll(ctx, keys)
+}
+
+// watch reloads the JWKS file whenever its directory changes.
+func (f *jwksFile) watch() {
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			if err := f.load(context.Background()); err != nil {
+				f.logger.Warnw("failed to reload jwks file", "error", err)
+
+				continue
+			}
+
+			f.logger.Debug("jwks file reloaded")
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			f.logger.Warnw("jwks file watcher error", "error", err)
+		}
+	}
+}
+
+// close stops watching the JWKS file.
+func (f *jwksFile) close() {
+	_ = f.watcher.Close() //nolint:errcheck // error check not needed
+}
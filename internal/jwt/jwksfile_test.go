@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	josejwt "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/testauth"
+)
+
+const fileIssuer = "https://issuer.example.com"
+
+type fileKey struct {
+	kid     string
+	privKey *rsa.PrivateKey
+}
+
+func newFileKey(t *testing.T, kid string) fileKey {
+	t.Helper()
+
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err, "no error expected generating key")
+
+	return fileKey{kid: kid, privKey: privKey}
+}
+
+func (k fileKey) sign(t *testing.T, issuer, subject string) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: k.privKey},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", k.kid),
+	)
+	require.NoError(t, err, "no error expected creating signer")
+
+	token, err := josejwt.Signed(signer).Claims(josejwt.Claims{
+		Issuer:   issuer,
+		Subject:  subject,
+		IssuedAt: josejwt.NewNumericDate(time.Now()),
+		Expiry:   josejwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).Serialize()
+	require.NoError(t, err, "no error expected signing token")
+
+	return token
+}
+
+func writeJWKSFile(t *testing.T, path string, keys ...fileKey) {
+	t.Helper()
+
+	jwks := jose.JSONWebKeySet{}
+
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, jose.JSONWebKey{
+			KeyID:     key.kid,
+			Key:       key.privKey.Public(),
+			Algorithm: string(jose.RS256),
+			Use:       "sig",
+		})
+	}
+
+	content, err := json.Marshal(jwks)
+	require.NoError(t, err, "no error expected marshaling jwks")
+
+	// Write and rename so the file is replaced atomically.
+	tmp := path + ".tmp"
+
+	require.NoError(t, os.WriteFile(tmp, content, 0o600), "no error expected writing jwks file")
+	require.NoError(t, os.Rename(tmp, path), "no error expected replacing jwks file")
+}
+
+func TestValidateTokenJWKSFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	key1 := newFileKey(t, "key-1")
+	key2 := newFileKey(t, "key-2")
+
+	writeJWKSFile(t, path, key1)
+
+	v, err := NewValidator(Config{
+		Issuer:   fileIssuer,
+		JWKSFile: path,
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	sub, _, err := v.ValidateToken(key1.sign(t, fileIssuer, "some-subject"))
+	require.NoError(t, err, "no error expected")
+	assert.Equal(t, "some-subject", sub, "unexpected subject")
+
+	_, _, err = v.ValidateToken(key2.sign(t, fileIssuer, "some-subject"))
+	require.Error(t, err, "expected token signed by unknown key to be invalid")
+
+	writeJWKSFile(t, path, key2)
+
+	assert.Eventually(t, func() bool {
+		_, _, err := v.ValidateToken(key2.sign(t, fileIssuer, "some-subject"))
+
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond, "expected rotated key to be loaded")
+
+	_, _, err = v.ValidateToken(key1.sign(t, fileIssuer, "some-subject"))
+	require.Error(t, err, "expected token signed by removed key to be invalid")
+
+	require.NoError(t, v.HealthCheck(t.Context()), "expected validator to be healthy")
+}
+
+func TestValidateTokenJWKSFileMergedWithURI(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	fileKey := newFileKey(t, "file-key")
+
+	writeJWKSFile(t, path, fileKey)
+
+	v, err := NewValidator(Config{
+		Issuer:   authsrv.Issuer,
+		JWKSURI:  authsrv.Issuer + "/.well-known/jwks.json",
+		JWKSFile: path,
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	expiry := testauth.Expiry(josejwt.NewNumericDate(time.Now().Add(time.Hour)))
+
+	_, _, err = v.ValidateToken(authsrv.TSignSubject(t, "uri-subject", expiry))
+	require.NoError(t, err, "expected token signed by uri key to be valid")
+
+	_, _, err = v.ValidateToken(fileKey.sign(t, authsrv.Issuer, "file-subject"))
+	require.NoError(t, err, "expected token signed by file key to be valid")
+}
+
+func TestNewValidatorJWKSFileMissing(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewValidator(Config{
+		Issuer:   fileIssuer,
+		JWKSFile: filepath.Join(t.TempDir(), "missing.json"),
+	}, zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, os.ErrNotExist, "expected file not found error")
+}
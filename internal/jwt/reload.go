@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+// ReloadableValidator is a validator whose configuration can be replaced while in use.
+type ReloadableValidator interface {
+	Validator
+
+	// Reload creates a validator with the new configuration and atomically replaces the current validator.
+	// The current validator remains in use when the new configuration is invalid.
+	Reload(config Config) error
+
+	// Close stops refreshing the JWKS of the current validator.
+	Close()
+}
+
+type reloadableValidator struct {
+	mu      sync.Mutex
+	current atomic.Pointer[validator]
+	logger  *zap.SugaredLogger
+}
+
+// NewReloadableValidator creates a reloadable validator with the given configuration.
+func NewReloadableValidator(config Config, logger *zap.SugaredLogger) (ReloadableValidator, error) {
+	v, err := NewValidator(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &reloadableValidator{
+		logger: logger,
+	}
+
+	out.current.Store(v.(*validator))
+
+	return out, nil
+}
+
+// Reload creates a validator with the new configuration and atomically replaces the current validator.
+// The current validator remains in use when the new configuration is invalid.
+func (r *reloadableValidator) Reload(config Config) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, err := NewValidator(config, r.logger)
+	if err != nil {
+		return err
+	}
+
+	r.current.Swap(v.(*validator)).close()
+
+	return nil
+}
+
+// ValidateToken validates the token with the current validator.
+func (r *reloadableValidator) ValidateToken(tokenString string) (string, map[string]any, error) {
+	return r.current.Load().ValidateToken(tokenString)
+}
+
+// HealthCheck returns nil when the current validator is healthy.
+func (r *reloadableValidator) HealthCheck(ctx context.Context) error {
+	return r.current.Load().HealthCheck(ctx)
+}
+
+// Close stops refreshing the JWKS of the current validator.
+func (r *reloadableValidator) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current.Load().close()
+}
@@ -0,0 +1,57 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/testauth"
+)
+
+func TestReloadableValidator(t *testing.T) {
+	authsrv1 := testauth.NewServer(t)
+	t.Cleanup(authsrv1.Stop)
+
+	authsrv2 := testauth.NewServer(t)
+	t.Cleanup(authsrv2.Stop)
+
+	expiry := testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour)))
+
+	token1 := authsrv1.TSignSubject(t, "subject-1", expiry)
+	token2 := authsrv2.TSignSubject(t, "subject-2", expiry)
+
+	v, err := NewReloadableValidator(Config{
+		Issuer:  authsrv1.Issuer,
+		JWKSURI: authsrv1.Issuer + "/.well-known/jwks.json",
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	_, _, err = v.ValidateToken(token1)
+	require.NoError(t, err, "expected first issuer to be trusted")
+
+	_, _, err = v.ValidateToken(token2)
+	require.ErrorIs(t, err, ErrUnknownIssuer, "expected second issuer to not be trusted")
+
+	err = v.Reload(Config{
+		Issuer:  authsrv2.Issuer,
+		JWKSURI: authsrv2.Issuer + "/.well-known/jwks.json",
+	})
+	require.NoError(t, err, "no error expected reloading")
+
+	_, _, err = v.ValidateToken(token1)
+	assert.ErrorIs(t, err, ErrUnknownIssuer, "expected first issuer to no longer be trusted")
+
+	sub, _, err := v.ValidateToken(token2)
+	require.NoError(t, err, "expected second issuer to be trusted")
+	assert.Equal(t, "subject-2", sub, "unexpected subject")
+
+	err = v.Reload(Config{Issuers: []IssuerConfig{{Issuer: authsrv1.Issuer}, {Issuer: authsrv1.Issuer}}})
+	require.ErrorIs(t, err, ErrDuplicateIssuer, "expected invalid config to be rejected")
+
+	_, _, err = v.ValidateToken(token2)
+	assert.NoError(t, err, "expected previous validator to remain in use")
+}
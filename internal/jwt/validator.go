@@ -3,16 +3,13 @@ package jwt
 import (
 	"context"
 	"errors"
-	"net/http"
-	"net/url"
+	"fmt"
 
-	"github.com/MicahParks/jwkset"
-	"github.com/MicahParks/keyfunc/v3"
 	"github.com/golang-jwt/jwt/v5"
-	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
 )
 
 const tracerName = "go.infratographer.com/iam-runtime-infratographer/internal/jwt"
@@ -24,6 +21,21 @@ var (
 	// ErrServiceDisabled is returned when calling a service method while the service is disabled.
 	ErrServiceDisabled = errors.New("jwt service disabled")
 
+	// ErrNoIssuers is returned when the validator is enabled without any issuers configured.
+	ErrNoIssuers = errors.New("no issuers configured")
+
+	// ErrDuplicateIssuer is returned when the same issuer is configured more than once.
+	ErrDuplicateIssuer = errors.New("duplicate issuer configured")
+
+	// ErrIssuerMissing is returned when the JWKS URI must be discovered but no issuer is configured.
+	ErrIssuerMissing = errors.New("issuer required to discover jwks uri")
+
+	// ErrIssuerRequired is returned when an issuer without a name is configured alongside other issuers.
+	ErrIssuerRequired = errors.New("issuer required when multiple issuers are configured")
+
+	// ErrUnknownIssuer is returned when a token is issued by an issuer which is not trusted.
+	ErrUnknownIssuer = errors.New("unknown issuer")
+
 	tracer = otel.GetTracerProvider().Tracer(tracerName)
 )
 
@@ -39,78 +51,135 @@ type Validator interface {
 
 type validator struct {
 	enabled bool
-	kf      jwt.Keyfunc
-	parser  *jwt.Parser
 
-	keyStorage jwkset.Storage
+	issuers     map[string]*issuer
+	issuerNames []string
+
+	audiences      []string
+	requiredClaims map[string]string
 }
 
 // NewValidator creates a validator with the given configuration.
-func NewValidator(config Config) (Validator, error) {
+func NewValidator(config Config, logger *zap.SugaredLogger) (Validator, error) {
 	if config.Disable {
 		return &validator{
 			enabled: false,
 		}, nil
 	}
 
-	transport := otelhttp.NewTransport(http.DefaultTransport)
-	client := &http.Client{
-		Transport: transport,
+	issuerConfigs := config.issuers()
+	if len(issuerConfigs) == 0 {
+		return nil, ErrNoIssuers
 	}
 
-	jwksURL, err := url.Parse(config.JWKSURI)
-	if err != nil {
-		return nil, err
+	parserOpts := []jwt.ParserOption{
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
 	}
 
-	storageOpts := jwkset.HTTPClientStorageOptions{
-		Client:          client,
-		RefreshInterval: config.JWKSRefreshInterval,
+	if len(config.Algorithms) != 0 {
+		parserOpts = append(parserOpts, jwt.WithValidMethods(config.Algorithms))
 	}
 
-	storage, err := jwkset.NewStorageFromHTTP(jwksURL.String(), storageOpts)
-	if err != nil {
-		return nil, err
-	}
+	out := &validator{
+		enabled: true,
+		issuers: make(map[string]*issuer, len(issuerConfigs)),
 
-	keyfuncOpts := keyfunc.Options{
-		Storage: storage,
+		audiences:      config.Audiences,
+		requiredClaims: config.RequiredClaims,
 	}
 
-	kf, err := keyfunc.New(keyfuncOpts)
-	if err != nil {
-		return nil, err
+	for i, issuerConfig := range issuerConfigs {
+		if issuerConfig.Issuer == "" && issuerConfig.JWKSURI == "" && issuerConfig.JWKSFile == "" {
+			return nil, ErrIssuerMissing
+		}
+
+		// An issuer without a name accepts tokens from any issuer, so it must be the only issuer.
+		if issuerConfig.Issuer == "" && len(issuerConfigs) > 1 {
+			return nil, ErrIssuerRequired
+		}
+
+		for _, other := range issuerConfigs[:i] {
+			if other.Issuer == issuerConfig.Issuer {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicateIssuer, issuerConfig.Issuer)
+			}
+		}
 	}
 
-	parser := jwt.NewParser(
-		jwt.WithIssuedAt(),
-		jwt.WithExpirationRequired(),
-		jwt.WithIssuer(config.Issuer),
-	)
+	for _, issuerConfig := range issuerConfigs {
+		iss, err := newIssuer(issuerConfig, parserOpts, logger)
+		if err != nil {
+			out.close()
 
-	out := &validator{
-		enabled: true,
-		kf:      kf.Keyfunc,
-		parser:  parser,
+			return nil, fmt.Errorf("issuer %s: %w", issuerConfig.Issuer, err)
+		}
 
-		keyStorage: storage,
+		out.issuers[iss.name] = iss
+		out.issuerNames = append(out.issuerNames, iss.name)
 	}
 
 	return out, nil
 }
 
+// close stops the background processes of all issuers.
+func (v *validator) close() {
+	for _, iss := range v.issuers {
+		iss.close()
+	}
+}
+
+// tokenIssuer returns the trusted issuer matching the unverified iss claim of the token.
+// When the only issuer is configured without a name, it is used for every token regardless of iss.
+func (v *validator) tokenIssuer(tokenString string) (*issuer, error) {
+	if iss, ok := v.issuers[""]; ok {
+		return iss, nil
+	}
+
+	unverifiedClaims := jwt.MapClaims{}
+
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverifiedClaims); err != nil {
+		return nil, err
+	}
+
+	name, err := unverifiedClaims.GetIssuer()
+	if err != nil {
+		return nil, err
+	}
+
+	iss, ok := v.issuers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w: %q", jwt.ErrTokenInvalidIssuer, ErrUnknownIssuer, name)
+	}
+
+	return iss, nil
+}
+
 func (v *validator) ValidateToken(tokenString string) (string, map[string]any, error) {
 	if !v.enabled {
 		return "", nil, ErrServiceDisabled
 	}
 
+	iss, err := v.tokenIssuer(tokenString)
+	if err != nil {
+		return "", nil, err
+	}
+
 	mapClaims := jwt.MapClaims{}
 
-	_, err := v.parser.ParseWithClaims(tokenString, mapClaims, v.kf)
+	_, err = iss.parser.ParseWithClaims(tokenString, mapClaims, iss.keyfunc)
 	if err != nil {
 		return "", nil, err
 	}
 
+	if err := validateAudience(mapClaims, v.audiences); err != nil {
+		return "", nil, fmt.Errorf("%w: %w", jwt.ErrTokenInvalidClaims, err)
+	}
+
+	if err := validateRequiredClaims(mapClaims, v.requiredClaims); err != nil {
+		return "", nil, err
+	}
+
 	sub, err := mapClaims.GetSubject()
 	if err != nil {
 		return "", nil, err
@@ -132,28 +201,20 @@ func (v *validator) HealthCheck(ctx context.Context) error {
 
 	span.SetAttributes(attribute.String("healthcheck.outcome", "unhealthy"))
 
-	keys, err := v.keyStorage.KeyReadAll(ctx)
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
-
-		return err
-	}
+	var errs []error
 
-	if len(keys) == 0 {
-		span.SetStatus(codes.Error, ErrIssuerKeysMissing.Error())
-		span.RecordError(ErrIssuerKeysMissing)
+	for _, name := range v.issuerNames {
+		if err := v.issuers[name].healthCheck(ctx); err != nil {
+			span.RecordError(err)
 
-		return ErrIssuerKeysMissing
+			errs = append(errs, err)
+		}
 	}
 
-	for _, key := range keys {
-		if err := key.Validate(); err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			span.RecordError(err)
+	if err := errors.Join(errs...); err != nil {
+		span.SetStatus(codes.Error, err.Error())
 
-			return err
-		}
+		return err
 	}
 
 	span.SetAttributes(attribute.String("healthcheck.outcome", "healthy"))
@@ -0,0 +1,372 @@
+package jwt
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4/jwt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/testauth"
+)
+
+func TestValidateToken(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	expiry := testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour)))
+
+	testCases := []struct {
+		name          string
+		config        Config
+		privateClaims map[string]any
+		options       []testauth.ClaimOption
+		expectError   error
+	}{
+		{
+			"valid",
+			Config{},
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"expired",
+			Config{},
+			nil,
+			[]testauth.ClaimOption{testauth.Expiry(jose.NewNumericDate(time.Now().Add(-time.Minute)))},
+			jwt.ErrTokenExpired,
+		},
+		{
+			"expired within leeway",
+			Config{Leeway: 5 * time.Minute},
+			nil,
+			[]testauth.ClaimOption{testauth.Expiry(jose.NewNumericDate(time.Now().Add(-time.Minute)))},
+			nil,
+		},
+		{
+			"audience matched",
+			Config{Audiences: []string{"other-service", "my-service"}},
+			nil,
+			[]testauth.ClaimOption{testauth.Audience("my-service")},
+			nil,
+		},
+		{
+			"audience mismatched",
+			Config{Audiences: []string{"my-service"}},
+			nil,
+			[]testauth.ClaimOption{testauth.Audience("other-service")},
+			jwt.ErrTokenInvalidAudience,
+		},
+		{
+			"audience missing",
+			Config{Audiences: []string{"my-service"}},
+			nil,
+			nil,
+			jwt.ErrTokenInvalidAudience,
+		},
+		{
+			"algorithm allowed",
+			Config{Algorithms: []string{"RS256"}},
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"algorithm not allowed",
+			Config{Algorithms: []string{"ES256"}},
+			nil,
+			nil,
+			jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			"required claim present",
+			Config{RequiredClaims: map[string]string{"tenant": ""}},
+			map[string]any{"tenant": "tnntten-abc"},
+			nil,
+			nil,
+		},
+		{
+			"required claim missing",
+			Config{RequiredClaims: map[string]string{"tenant": ""}},
+			nil,
+			nil,
+			ErrRequiredClaimMissing,
+		},
+		{
+			"required scope contained",
+			Config{RequiredClaims: map[string]string{"scope": "read write"}},
+			map[string]any{"scope": "openid read write"},
+			nil,
+			nil,
+		},
+		{
+			"required scope not contained",
+			Config{RequiredClaims: map[string]string{"scope": "admin"}},
+			map[string]any{"scope": "openid read write"},
+			nil,
+			ErrRequiredClaimMismatch,
+		},
+		{
+			"required array claim contained",
+			Config{RequiredClaims: map[string]string{"groups": "admins"}},
+			map[string]any{"groups": []string{"users", "admins"}},
+			nil,
+			nil,
+		},
+		{
+			"required number claim matched",
+			Config{RequiredClaims: map[string]string{"level": "5"}},
+			map[string]any{"level": 5},
+			nil,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := tc.config
+
+			config.Issuer = authsrv.Issuer
+			config.JWKSURI = authsrv.Issuer + "/.well-known/jwks.json"
+
+			v, err := NewValidator(config, zap.NewNop().Sugar())
+			require.NoError(t, err, "no error expected creating validator")
+
+			options := append([]testauth.ClaimOption{expiry}, tc.options...)
+
+			token := authsrv.TSignSubjectWithClaims(t, "some-subject", tc.privateClaims, options...)
+
+			sub, claims, err := v.ValidateToken(token)
+
+			if tc.expectError != nil {
+				require.Error(t, err, "error expected")
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			assert.Equal(t, "some-subject", sub, "unexpected subject")
+			assert.NotEmpty(t, claims, "expected claims to be returned")
+		})
+	}
+}
+
+func TestValidatorDisabled(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewValidator(Config{Disable: true}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	_, _, err = v.ValidateToken("token")
+
+	assert.ErrorIs(t, err, ErrServiceDisabled, "expected service disabled error")
+}
+
+func TestValidateTokenMultipleIssuers(t *testing.T) {
+	authsrv1 := testauth.NewServer(t)
+	t.Cleanup(authsrv1.Stop)
+
+	authsrv2 := testauth.NewServer(t)
+	t.Cleanup(authsrv2.Stop)
+
+	untrusted := testauth.NewServer(t)
+	t.Cleanup(untrusted.Stop)
+
+	v, err := NewValidator(Config{
+		Issuer:  authsrv1.Issuer,
+		JWKSURI: authsrv1.Issuer + "/.well-known/jwks.json",
+		Issuers: []IssuerConfig{
+			{
+				Issuer:  authsrv2.Issuer,
+				JWKSURI: authsrv2.Issuer + "/.well-known/jwks.json",
+			},
+		},
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	expiry := testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour)))
+
+	testCases := []struct {
+		name        string
+		token       string
+		expectError error
+	}{
+		{"first issuer", authsrv1.TSignSubject(t, "subject-1", expiry), nil},
+		{"second issuer", authsrv2.TSignSubject(t, "subject-2", expiry), nil},
+		{"untrusted issuer", untrusted.TSignSubject(t, "subject-3", expiry), ErrUnknownIssuer},
+		{"malformed", "not-a-jwt", jwt.ErrTokenMalformed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := v.ValidateToken(tc.token)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			assert.NoError(t, err, "no error expected")
+		})
+	}
+
+	require.NoError(t, v.HealthCheck(context.Background()), "expected validator to be healthy")
+}
+
+func TestValidatorHealthCheckReportsIssuer(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	emptyJWKS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"keys":[]}`))
+	}))
+	t.Cleanup(emptyJWKS.Close)
+
+	v, err := NewValidator(Config{
+		Issuers: []IssuerConfig{
+			{
+				Issuer:  authsrv.Issuer,
+				JWKSURI: authsrv.Issuer + "/.well-known/jwks.json",
+			},
+			{
+				Issuer:  "https://missing.example.com",
+				JWKSURI: emptyJWKS.URL,
+			},
+		},
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	err = v.HealthCheck(context.Background())
+
+	require.Error(t, err, "expected health check to fail")
+	assert.ErrorIs(t, err, ErrIssuerKeysMissing, "unexpected error returned")
+	assert.ErrorContains(t, err, "https://missing.example.com", "expected error to identify issuer")
+	assert.NotContains(t, err.Error(), authsrv.Issuer, "expected healthy issuer to not be reported")
+}
+
+func TestNewValidatorDuplicateIssuer(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewValidator(Config{
+		Issuer:  "https://issuer.example.com",
+		JWKSURI: "https://issuer.example.com/jwks.json",
+		Issuers: []IssuerConfig{
+			{
+				Issuer:  "https://issuer.example.com",
+				JWKSURI: "https://issuer.example.com/jwks.json",
+			},
+		},
+	}, zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, ErrDuplicateIssuer, "expected duplicate issuer error")
+}
+
+func TestValidateTokenDiscoversJWKSURI(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	v, err := NewValidator(Config{
+		Issuer: authsrv.Issuer,
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	expiry := testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour)))
+
+	sub, _, err := v.ValidateToken(authsrv.TSignSubject(t, "some-subject", expiry))
+	require.NoError(t, err, "no error expected")
+	assert.Equal(t, "some-subject", sub, "unexpected subject")
+}
+
+func TestIssuerRefreshDiscovery(t *testing.T) {
+	keys1 := testauth.NewServer(t)
+	t.Cleanup(keys1.Stop)
+
+	keys2 := testauth.NewServer(t)
+	t.Cleanup(keys2.Stop)
+
+	var jwksURI atomic.Value
+
+	jwksURI.Store(keys1.Issuer + "/.well-known/jwks.json")
+
+	discovery := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"jwks_uri":%q}`, jwksURI.Load())
+	}))
+	t.Cleanup(discovery.Close)
+
+	iss, err := newIssuer(IssuerConfig{Issuer: discovery.URL}, nil, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating issuer")
+
+	options := []testauth.ClaimOption{
+		testauth.Issuer(discovery.URL),
+		testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour))),
+	}
+
+	token1 := keys1.TSignSubject(t, "some-subject", options...)
+	token2 := keys2.TSignSubject(t, "some-subject", options...)
+
+	_, err = iss.parser.Parse(token1, iss.keyfunc)
+	require.NoError(t, err, "expected token signed by first keys to be valid")
+
+	jwksURI.Store(keys2.Issuer + "/.well-known/jwks.json")
+
+	require.NoError(t, iss.refreshDiscovery(context.Background()), "no error expected refreshing discovery")
+
+	_, err = iss.parser.Parse(token2, iss.keyfunc)
+	require.NoError(t, err, "expected token signed by second keys to be valid")
+
+	_, err = iss.parser.Parse(token1, iss.keyfunc)
+	require.Error(t, err, "expected token signed by first keys to be invalid")
+}
+
+func TestNewValidatorIssuerMissing(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewValidator(Config{
+		Issuers: []IssuerConfig{{}},
+	}, zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, ErrIssuerMissing, "expected issuer missing error")
+}
+
+func TestValidateTokenJWKSURIOnly(t *testing.T) {
+	authsrv := testauth.NewServer(t)
+	t.Cleanup(authsrv.Stop)
+
+	v, err := NewValidator(Config{
+		JWKSURI: authsrv.Issuer + "/.well-known/jwks.json",
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating validator")
+
+	expiry := testauth.Expiry(jose.NewNumericDate(time.Now().Add(time.Hour)))
+
+	sub, _, err := v.ValidateToken(authsrv.TSignSubject(t, "some-subject", expiry))
+	require.NoError(t, err, "expected token from any issuer to be accepted")
+	assert.Equal(t, "some-subject", sub, "unexpected subject")
+}
+
+func TestNewValidatorIssuerRequired(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewValidator(Config{
+		JWKSURI: "https://issuer.example.com/jwks.json",
+		Issuers: []IssuerConfig{
+			{
+				Issuer:  "https://other.example.com",
+				JWKSURI: "https://other.example.com/jwks.json",
+			},
+		},
+	}, zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, ErrIssuerRequired, "expected issuer required error")
+}
@@ -0,0 +1,2 @@
+// Package metrics provides Prometheus metrics for the IAM runtime.
+package metrics
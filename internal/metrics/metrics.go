@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "iam_runtime"
+
+var (
+	// Registry is the registry all runtime metrics are registered with.
+	Registry = prometheus.NewRegistry()
+
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "requests_total",
+		Help:      "Total number of gRPC requests by method and outcome.",
+	}, []string{"method", "outcome"})
+
+	grpcRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "request_duration_seconds",
+		Help:      "gRPC request latency by method and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	permissionsRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "permissions",
+		Name:      "http_request_duration_seconds",
+		Help:      "permissions-api HTTP request attempt latency by path and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"path", "code"})
+
+	permissionsRetries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "permissions",
+		Name:      "http_retries_total",
+		Help:      "Total number of permissions-api HTTP request retries, including host failovers.",
+	})
+
+	permissionsRetriesSkipped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "permissions",
+		Name:      "http_retries_skipped_total",
+		Help:      "Total number of permissions-api HTTP request retries skipped by reason.",
+	}, []string{"reason"})
+
+	jwksRefreshFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "jwt",
+		Name:      "jwks_refresh_failures_total",
+		Help:      "Total number of failed JWKS refreshes by issuer.",
+	}, []string{"issuer"})
+
+	tokenSourceRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accesstoken",
+		Name:      "refreshes_total",
+		Help:      "Total number of access token refreshes by outcome.",
+	}, []string{"outcome"})
+
+	auditWriteFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "write_failures_total",
+		Help:      "Total number of audit events which failed to be written by sink.",
+	}, []string{"sink"})
+
+	auditDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "audit",
+		Name:      "dropped_total",
+		Help:      "Total number of audit events lost because the audit queue was full.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		grpcRequests,
+		grpcRequestDuration,
+		permissionsRequestDuration,
+		permissionsRetries,
+		permissionsRetriesSkipped,
+		jwksRefreshFailures,
+		tokenSourceRefreshes,
+		auditWriteFailures,
+		auditDropped,
+	)
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Handler returns an HTTP handler serving the runtime metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveGRPCRequest records a gRPC request.
+func ObserveGRPCRequest(method, outcome string, duration time.Duration) {
+	grpcRequests.WithLabelValues(method, outcome).Inc()
+	grpcRequestDuration.WithLabelValues(method, outcome).Observe(duration.Seconds())
+}
+
+// InstrumentPermissionsTransport records the latency of every permissions-api HTTP request attempt
+// made through the base round tripper.
+func InstrumentPermissionsTransport(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		start := time.Now()
+
+		resp, err := base.RoundTrip(r)
+
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+
+		permissionsRequestDuration.WithLabelValues(r.URL.Path, code).Observe(time.Since(start).Seconds())
+
+		return resp, err
+	})
+}
+
+// IncPermissionsRetries records a permissions-api HTTP request retry or host failover.
+func IncPermissionsRetries() {
+	permissionsRetries.Inc()
+}
+
+// IncPermissionsRetriesSkipped records a permissions-api HTTP request retry which was skipped,
+// either because the request deadline would be exceeded or the retry budget is exhausted.
+func IncPermissionsRetriesSkipped(reason string) {
+	permissionsRetriesSkipped.WithLabelValues(reason).Inc()
+}
+
+// IncJWKSRefreshFailures records a failed JWKS refresh for the issuer.
+func IncJWKSRefreshFailures(issuer string) {
+	jwksRefreshFailures.WithLabelValues(issuer).Inc()
+}
+
+// IncTokenSourceRefreshes records an access token refresh.
+func IncTokenSourceRefreshes(outcome string) {
+	tokenSourceRefreshes.WithLabelValues(outcome).Inc()
+}
+
+// IncAuditWriteFailures records an audit event which failed to be written to the sink.
+func IncAuditWriteFailures(sink string) {
+	auditWriteFailures.WithLabelValues(sink).Inc()
+}
+
+// IncAuditDropped records an audit event dropped because the audit queue was full.
+func IncAuditDropped() {
+	auditDropped.Inc()
+}
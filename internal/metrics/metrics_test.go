@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+func TestSelectorCollector(t *testing.T) {
+	t.Parallel()
+
+	selector, err := selecthost.NewSelector("permissions-api.example.com:443", "permissions-api", "tcp", selecthost.Quick())
+	require.NoError(t, err, "no error expected creating selector")
+
+	t.Cleanup(selector.Stop)
+
+	collector := NewSelectorCollector(selector)
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector, "iam_runtime_selecthost_selected_host"), "expected no host selected before start")
+
+	host, err := selector.GetHost(context.Background())
+	require.NoError(t, err, "no error expected getting host")
+	require.NotNil(t, host, "expected host to be selected")
+
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "iam_runtime_selecthost_selected_host"), "expected selected host to be reported")
+
+	problems, err := testutil.CollectAndLint(collector)
+	require.NoError(t, err, "no error expected linting metrics")
+	assert.Empty(t, problems, "expected no lint problems")
+}
+
+func TestRegisterSelector(t *testing.T) {
+	t.Parallel()
+
+	selector, err := selecthost.NewSelector("register.example.com:443", "permissions-api", "tcp", selecthost.Quick())
+	require.NoError(t, err, "no error expected creating selector")
+
+	unregister, err := RegisterSelector(selector)
+	require.NoError(t, err, "no error expected registering selector")
+
+	_, err = RegisterSelector(selector)
+	require.ErrorAs(t, err, &prometheus.AlreadyRegisteredError{}, "expected selector for the same target to already be registered")
+
+	unregister()
+
+	unregister, err = RegisterSelector(selector)
+	require.NoError(t, err, "expected selector to be registered again once unregistered")
+
+	unregister()
+}
+
+func TestInstrumentPermissionsTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := &http.Client{
+		Transport: InstrumentPermissionsTransport(http.DefaultTransport),
+	}
+
+	before := testutil.CollectAndCount(permissionsRequestDuration)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/test/instrument", nil)
+	require.NoError(t, err, "no error expected creating request")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err, "no error expected")
+
+	_ = resp.Body.Close() //nolint:errcheck // error check not needed
+
+	assert.Equal(t, before+1, testutil.CollectAndCount(permissionsRequestDuration), "expected request to be recorded")
+	assert.Equal(t, uint64(1), histogramCount(t, "/test/instrument", "403"), "expected request to be recorded with path and code")
+}
+
+func histogramCount(t *testing.T, labels ...string) uint64 {
+	t.Helper()
+
+	observer, err := permissionsRequestDuration.GetMetricWithLabelValues(labels...)
+	require.NoError(t, err, "no error expected getting metric")
+
+	metric := &dto.Metric{}
+
+	require.NoError(t, observer.(prometheus.Metric).Write(metric), "no error expected writing metric")
+
+	return metric.GetHistogram().GetSampleCount()
+}
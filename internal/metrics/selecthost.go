@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+// selectorCollector reports the host health and selected host of a [selecthost.Selector].
+type selectorCollector struct {
+	selector *selecthost.Selector
+
+	selectedDesc      *prometheus.Desc
+	healthyDesc       *prometheus.Desc
+	checkDurationDesc *prometheus.Desc
+	outstandingDesc   *prometheus.Desc
+}
+
+// NewSelectorCollector creates a collector reporting on the provided selector.
+func NewSelectorCollector(selector *selecthost.Selector) prometheus.Collector {
+	constLabels := prometheus.Labels{"target": selector.Target()}
+
+	return &selectorCollector{
+		selector: selector,
+
+		selectedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "selecthost", "selected_host"),
+			"The currently selected host, set to 1 for the selected host.",
+			[]string{"host"}, constLabels,
+		),
+		healthyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "selecthost", "host_healthy"),
+			"Whether the discovered host passed its last health check.",
+			[]string{"host"}, constLabels,
+		),
+		checkDurationDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "selecthost", "host_check_duration_seconds"),
+			"The average duration of the last health check of the discovered host.",
+			[]string{"host"}, constLabels,
+		),
+		outstandingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "selecthost", "host_outstanding_requests"),
+			"The number of requests currently being sent to the discovered host.",
+			[]string{"host"}, constLabels,
+		),
+	}
+}
+
+// RegisterSelector registers a collector for the provided selector with the runtime registry.
+// The returned function unregisters the collector, allowing a selector for the same target to be
+// registered once the selector is no longer used.
+func RegisterSelector(selector *selecthost.Selector) (func(), error) {
+	collector := NewSelectorCollector(selector)
+
+	if err := Registry.Register(collector); err != nil {
+		return nil, err
+	}
+
+	return func() { Registry.Unregister(collector) }, nil
+}
+
+// Describe implements [prometheus.Collector].
+func (c *selectorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.selectedDesc
+	ch <- c.healthyDesc
+	ch <- c.checkDurationDesc
+	ch <- c.outstandingDesc
+}
+
+// Collect implements [prometheus.Collector].
+func (c *selectorCollector) Collect(ch chan<- prometheus.Metric) {
+	if selected := c.selector.SelectedHost(); selected != nil {
+		ch <- prometheus.MustNewConstMetric(c.selectedDesc, prometheus.GaugeValue, 1, selected.ID())
+	}
+
+	for _, host := range c.selector.Hosts() {
+		var healthy float64
+
+		if host.Err() == nil {
+			healthy = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.healthyDesc, prometheus.GaugeValue, healthy, host.ID())
+		ch <- prometheus.MustNewConstMetric(c.checkDurationDesc, prometheus.GaugeValue, host.AverageDuration().Seconds(), host.ID())
+		ch <- prometheus.MustNewConstMetric(c.outstandingDesc, prometheus.GaugeValue, float64(host.Outstanding()), host.ID())
+	}
+}
@@ -1,6 +1,8 @@
 package otelx
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
@@ -11,6 +13,26 @@ type Config struct {
 	SampleRatio float64 `mapstructure:"sample_ratio"`
 	URL         string
 	Insecure    bool
+
+	// Metrics configures exporting OpenTelemetry metrics to the collector at URL.
+	Metrics MetricsConfig
+	// Logs configures exporting logs to the collector at URL.
+	Logs LogsConfig
+}
+
+// MetricsConfig represents the OpenTelemetry metrics export configuration.
+type MetricsConfig struct {
+	// Enabled exports OpenTelemetry metrics, including all runtime Prometheus metrics.
+	Enabled bool
+	// Interval is the interval metrics are exported at.
+	// Default: 60s
+	Interval time.Duration
+}
+
+// LogsConfig represents the OpenTelemetry logs export configuration.
+type LogsConfig struct {
+	// Enabled exports logs as OpenTelemetry logs, in addition to writing them to stderr.
+	Enabled bool
 }
 
 // AddFlags sets the command line flags for OpenTelemetry instrumentation.
@@ -20,4 +42,7 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.Float64("tracing.sample-ratio", 1.0, "ratio of traces sampled (0.0 - 1.0)")
 	flags.String("tracing.url", "", "gRPC URL for OpenTelemetry collector")
 	flags.Bool("tracing.insecure", false, "true if TLS should be disabled")
+	flags.Bool("tracing.metrics.enabled", false, "true if metrics should be exported to the OpenTelemetry collector")
+	flags.Duration("tracing.metrics.interval", time.Minute, "interval metrics are exported at")
+	flags.Bool("tracing.logs.enabled", false, "true if logs should be exported to the OpenTelemetry collector")
 }
@@ -0,0 +1,6 @@
+package otelx
+
+import "errors"
+
+// ErrURLRequired is returned when metrics or logs export is enabled without a collector URL.
+var ErrURLRequired = errors.New("tracing.url is required to export metrics and logs")
@@ -0,0 +1,58 @@
+package otelx
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// initLogs returns a logger which writes to the provided logger and exports to the collector.
+func initLogs(ctx context.Context, config Config, appName string, res *resource.Resource, logger *zap.SugaredLogger) (*zap.SugaredLogger, ShutdownFunc, error) {
+	if config.URL == "" {
+		return nil, nil, ErrURLRequired
+	}
+
+	opts := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(config.URL),
+	}
+
+	if config.Insecure {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	}
+
+	exporter, err := otlploggrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+
+	base := logger.Desugar()
+
+	// The bridge core enables all levels, limit it to the levels enabled by the existing logger.
+	otelCore, err := zapcore.NewIncreaseLevelCore(otelzap.NewCore(appName, otelzap.WithLoggerProvider(provider)), base.Core())
+	if err != nil {
+		return nil, nil, errors.Join(err, provider.Shutdown(ctx))
+	}
+
+	otelLogger := base.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, otelCore)
+	}))
+
+	return otelLogger.Sugar(), provider.Shutdown, nil
+}
+
+// ContextField returns a logger field carrying the context. Exported logs are correlated with the trace
+// and span in the context, the field is not written to stderr.
+func ContextField(ctx context.Context) zap.Field {
+	return zap.Field{Key: "context", Type: zapcore.SkipType, Interface: ctx}
+}
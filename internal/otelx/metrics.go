@@ -0,0 +1,51 @@
+package otelx
+
+import (
+	"context"
+
+	otelprom "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+)
+
+// initMetrics sets the global meter provider to one periodically exporting to the collector.
+// The runtime Prometheus metrics are exported alongside the OpenTelemetry instrumentation metrics.
+func initMetrics(ctx context.Context, config Config, res *resource.Resource) (ShutdownFunc, error) {
+	if config.URL == "" {
+		return nil, ErrURLRequired
+	}
+
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(config.URL),
+	}
+
+	if config.Insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	exporter, err := otlpmetricgrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	readerOpts := []sdkmetric.PeriodicReaderOption{
+		sdkmetric.WithProducer(otelprom.NewMetricProducer(otelprom.WithGatherer(metrics.Registry))),
+	}
+
+	if config.Metrics.Interval > 0 {
+		readerOpts = append(readerOpts, sdkmetric.WithInterval(config.Metrics.Interval))
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOpts...)),
+	)
+
+	otel.SetMeterProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -1,12 +1,21 @@
 package otelx
 
 import (
+	"context"
+	"errors"
+
 	"go.infratographer.com/x/otelx"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.uber.org/zap"
 )
 
-// Initialize sets up OpenTelemetry instrumentation.
-func Initialize(config Config, appName string) error {
+// ShutdownFunc flushes and stops the OpenTelemetry exporters.
+type ShutdownFunc func(ctx context.Context) error
+
+// Initialize sets up OpenTelemetry instrumentation. The returned logger additionally exports its logs
+// when log export is enabled, otherwise the provided logger is returned.
+func Initialize(ctx context.Context, config Config, appName string, logger *zap.SugaredLogger) (*zap.SugaredLogger, ShutdownFunc, error) {
 	otelConfig := otelx.Config{
 		Enabled:     config.Enabled,
 		Provider:    otelx.ExporterOTLPGRPC,
@@ -18,5 +27,52 @@ func Initialize(config Config, appName string) error {
 		},
 	}
 
-	return otelx.InitTracer(otelConfig, appName, zap.NewNop().Sugar())
+	if err := otelx.InitTracer(otelConfig, appName, zap.NewNop().Sugar()); err != nil {
+		return nil, nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(
+			semconv.ServiceName(appName),
+			semconv.DeploymentEnvironmentName(config.Environment),
+		),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var shutdowns []ShutdownFunc
+
+	shutdown := func(ctx context.Context) error {
+		var errs []error
+
+		for _, fn := range shutdowns {
+			errs = append(errs, fn(ctx))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	if config.Metrics.Enabled {
+		metricsShutdown, err := initMetrics(ctx, config, res)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		shutdowns = append(shutdowns, metricsShutdown)
+	}
+
+	if config.Logs.Enabled {
+		otelLogger, logsShutdown, err := initLogs(ctx, config, appName, res, logger)
+		if err != nil {
+			return nil, nil, errors.Join(err, shutdown(ctx))
+		}
+
+		logger = otelLogger
+
+		shutdowns = append(shutdowns, logsShutdown)
+	}
+
+	return logger, shutdown, nil
 }
@@ -0,0 +1,59 @@
+package otelx
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestInitialize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		config      Config
+		expectError error
+	}{
+		{"disabled", Config{}, nil},
+		{"metrics without url", Config{Metrics: MetricsConfig{Enabled: true}}, ErrURLRequired},
+		{"logs without url", Config{Logs: LogsConfig{Enabled: true}}, ErrURLRequired},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			logger := zap.NewNop().Sugar()
+
+			out, shutdown, err := Initialize(context.Background(), tc.config, "test", logger)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			assert.Same(t, logger, out, "expected logger to be unchanged")
+			assert.NoError(t, shutdown(context.Background()), "no error expected shutting down")
+		})
+	}
+}
+
+func TestContextFieldNotEncoded(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zap.InfoLevel)
+
+	zap.New(core).Sugar().Infow("test", "key", "value", ContextField(context.Background()))
+
+	assert.Contains(t, buf.String(), `"key":"value"`, "expected fields to be written")
+	assert.NotContains(t, buf.String(), "context", "expected context field to be skipped")
+}
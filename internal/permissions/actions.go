@@ -0,0 +1,98 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/sync/errgroup"
+)
+
+// ActionResult represents the outcome of checking access to a single [RequestAction].
+type ActionResult struct {
+	RequestAction
+
+	// Allowed is true when the subject is allowed to perform the action on the resource.
+	Allowed bool
+}
+
+// CheckActions evaluates every action individually and returns a result for each action,
+// in the order they were provided.
+//
+// The batch is first checked as a whole, so if every action is allowed a single request is made.
+// Only when the batch is denied are the actions checked individually to determine which were denied.
+func (c *client) CheckActions(ctx context.Context, subjToken string, actions []RequestAction) ([]ActionResult, error) {
+	ctx, span := c.tracer.Start(ctx, "CheckActions")
+	defer span.End()
+
+	results := make([]ActionResult, len(actions))
+
+	for i, action := range actions {
+		results[i] = ActionResult{
+			RequestAction: action,
+		}
+	}
+
+	err := c.CheckAccess(ctx, subjToken, actions)
+
+	switch {
+	case err == nil:
+		for i := range results {
+			results[i].Allowed = true
+		}
+
+		span.SetAttributes(attribute.Int("permissions.actions.allowed", len(results)))
+
+		return results, nil
+	case len(actions) == 1 && errors.Is(err, ErrPermissionDenied):
+		span.SetAttributes(attribute.Int("permissions.actions.denied", 1))
+
+		return results, nil
+	case !errors.Is(err, ErrPermissionDenied):
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	group.SetLimit(c.perActionConcurrency)
+
+	for i := range results {
+		group.Go(func() error {
+			err := c.CheckAccess(groupCtx, subjToken, []RequestAction{results[i].RequestAction})
+
+			switch {
+			case err == nil:
+				results[i].Allowed = true
+			case errors.Is(err, ErrPermissionDenied):
+			default:
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	var allowed int
+
+	for _, result := range results {
+		if result.Allowed {
+			allowed++
+		}
+	}
+
+	span.SetAttributes(
+		attribute.Int("permissions.actions.allowed", allowed),
+		attribute.Int("permissions.actions.denied", len(results)-allowed),
+	)
+
+	return results, nil
+}
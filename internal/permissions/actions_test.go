@@ -0,0 +1,93 @@
+package permissions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckActions(t *testing.T) {
+	t.Parallel()
+
+	read := RequestAction{Action: "read", ResourceID: "resource-1"}
+	write := RequestAction{Action: "write", ResourceID: "resource-1"}
+	remove := RequestAction{Action: "delete", ResourceID: "resource-1"}
+
+	testCases := []struct {
+		name           string
+		denied         map[RequestAction]bool
+		unauthorized   bool
+		actions        []RequestAction
+		expectAllowed  []bool
+		expectRequests int32
+		expectError    error
+	}{
+		{"all allowed", nil, false, []RequestAction{read, write, remove}, []bool{true, true, true}, 1, nil},
+		{"single denied", map[RequestAction]bool{read: true}, false, []RequestAction{read}, []bool{false}, 1, nil},
+		{"some denied", map[RequestAction]bool{write: true}, false, []RequestAction{read, write, remove}, []bool{true, false, true}, 4, nil},
+		{"all denied", map[RequestAction]bool{read: true, write: true}, false, []RequestAction{read, write}, []bool{false, false}, 3, nil},
+		{"unauthenticated", nil, true, []RequestAction{read, write}, nil, 1, ErrUnauthenticated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+
+			c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+
+				if tc.unauthorized {
+					w.WriteHeader(http.StatusUnauthorized)
+
+					return
+				}
+
+				var body checkPermissionRequest
+
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+
+					return
+				}
+
+				for _, action := range body.Actions {
+					if tc.denied[action] {
+						w.WriteHeader(http.StatusForbidden)
+
+						return
+					}
+				}
+
+				w.WriteHeader(http.StatusOK)
+			})
+
+			c.perActionConcurrency = 2
+
+			results, err := c.CheckActions(context.Background(), "token", tc.actions)
+
+			assert.Equal(t, tc.expectRequests, requests.Load(), "unexpected number of upstream requests")
+
+			if tc.expectError != nil {
+				require.Error(t, err, "error expected")
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			require.Len(t, results, len(tc.actions), "expected a result for each action")
+
+			for i, result := range results {
+				assert.Equal(t, tc.actions[i], result.RequestAction, "result %d: unexpected action order", i)
+				assert.Equal(t, tc.expectAllowed[i], result.Allowed, "result %d: unexpected allowed result", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package permissions
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// decisionKey identifies a cached decision for a single (subject, action, resource) tuple.
+// The subject token is stored as a hash so raw credentials are never held in memory by the cache.
+type decisionKey struct {
+	subject    [sha256.Size]byte
+	action     string
+	resourceID string
+}
+
+type decisionEntry struct {
+	key     decisionKey
+	allowed bool
+	decided time.Time
+	expires time.Time
+}
+
+// decisionCache is a bounded, least recently used cache of permissions-api decisions.
+type decisionCache struct {
+	mu sync.Mutex
+
+	allowTTL   time.Duration
+	denyTTL    time.Duration
+	maxEntries int
+
+	entries map[decisionKey]*list.Element
+	lru     *list.List
+
+	now func() time.Time
+}
+
+func newDecisionCache(cfg CacheConfig) *decisionCache {
+	cfg = cfg.withDefaults()
+
+	return &decisionCache{
+		allowTTL:   cfg.AllowTTL,
+		denyTTL:    cfg.DenyTTL,
+		maxEntries: cfg.MaxEntries,
+		entries:    make(map[decisionKey]*list.Element),
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+func hashSubject(subjToken string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(subjToken))
+}
+
+// get returns the cached decision for the provided key.
+// Expired entries are removed and reported as a miss.
+func (c *decisionCache) get(key decisionKey) (allowed bool, ok bool) {
+	allowed, _, ok = c.getAt(key, c.now())
+
+	return allowed, ok
+}
+
+// getAt returns the cached decision for the provided key at the given time, along with the time
+// the decision was made. Expired entries are removed and reported as a miss.
+func (c *decisionCache) getAt(key decisionKey, now time.Time) (allowed bool, decided time.Time, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return false, time.Time{}, false
+	}
+
+	entry := elem.Value.(*decisionEntry)
+
+	if !now.Before(entry.expires) {
+		c.removeElement(elem)
+
+		return false, time.Time{}, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return entry.allowed, entry.decided, true
+}
+
+// set stores the decision for the provided key, evicting the least recently used
+// entries when the cache is full.
+func (c *decisionCache) set(key decisionKey, allowed bool) {
+	ttl := c.denyTTL
+	if allowed {
+		ttl = c.allowTTL
+	}
+
+	if ttl <= 0 {
+		return
+	}
+
+	now := c.now()
+
+	c.put(key, allowed, now, now.Add(ttl))
+}
+
+// put stores the decision made at the decided time until it expires, evicting the least recently
+// used entries when the cache is full.
+func (c *decisionCache) put(key decisionKey, allowed bool, decided, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*decisionEntry)
+
+		entry.allowed = allowed
+		entry.decided = decided
+		entry.expires = expires
+
+		c.lru.MoveToFront(elem)
+
+		return
+	}
+
+	for c.lru.Len() >= c.maxEntries {
+		c.removeElement(c.lru.Back())
+	}
+
+	c.entries[key] = c.lru.PushFront(&decisionEntry{
+		key:     key,
+		allowed: allowed,
+		decided: decided,
+		expires: expires,
+	})
+}
+
+func (c *decisionCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*decisionEntry)
+
+	delete(c.entries, entry.key)
+}
+
+// len returns the number of entries currently held by the cache.
+func (c *decisionCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// cacheLookup holds the outcome of looking up a batch of actions in the cache.
+type cacheLookup struct {
+	hits   int
+	misses int
+	denied bool
+}
+
+// decided returns true when the cached results are enough to answer the request without
+// contacting permissions-api, either because an action was denied or all actions were allowed.
+func (l cacheLookup) decided() bool {
+	return l.denied || (l.misses == 0 && l.hits != 0)
+}
+
+// lookup checks every action in the batch against the cache.
+func (c *decisionCache) lookup(subject [sha256.Size]byte, actions []RequestAction) cacheLookup {
+	var result cacheLookup
+
+	for _, action := range actions {
+		allowed, ok := c.get(decisionKey{subject, action.Action, action.ResourceID})
+
+		switch {
+		case !ok:
+			result.misses++
+		case allowed:
+			result.hits++
+		default:
+			result.hits++
+			result.denied = true
+		}
+	}
+
+	return result
+}
+
+// store records the outcome of a permissions-api request.
+// An allowed response allows every action in the batch, so each is cached individually.
+// A denied response only identifies the denied action when the batch contained a single action,
+// so denials of larger batches are not cached.
+func (c *decisionCache) store(subject [sha256.Size]byte, actions []RequestAction, allowed bool) {
+	if !allowed && len(actions) != 1 {
+		return
+	}
+
+	for _, action := range actions {
+		c.set(decisionKey{subject, action.Action, action.ResourceID}, allowed)
+	}
+}
+
+// StaleCache is a bounded, least recently used cache of decisions made by permissions-api, kept
+// to serve decisions while permissions-api is unavailable. Unlike the client decision cache, each
+// decision is kept until the expiry it is stored with.
+type StaleCache struct {
+	cache *decisionCache
+}
+
+// NewStaleCache creates a stale decision cache holding up to maxEntries action decisions.
+func NewStaleCache(maxEntries int) *StaleCache {
+	return &StaleCache{
+		cache: &decisionCache{
+			maxEntries: maxEntries,
+			entries:    make(map[decisionKey]*list.Element),
+			lru:        list.New(),
+			now:        time.Now,
+		},
+	}
+}
+
+// Store records the decision made at the decided time for each action requested with the
+// credential. Decisions are served until they expire.
+func (c *StaleCache) Store(credential string, actions []RequestAction, allowed []bool, decided, expires time.Time) {
+	subject := hashSubject(credential)
+
+	for i, action := range actions {
+		if i >= len(allowed) {
+			break
+		}
+
+		c.cache.put(decisionKey{subject, action.Action, action.ResourceID}, allowed[i], decided, expires)
+	}
+}
+
+// Load returns the decision for each action requested with the credential at the given time,
+// along with the age of the oldest decision. False is returned unless every action has an
+// unexpired decision.
+func (c *StaleCache) Load(credential string, actions []RequestAction, now time.Time) ([]bool, time.Duration, bool) {
+	subject := hashSubject(credential)
+
+	var (
+		allowed = make([]bool, len(actions))
+		age     time.Duration
+	)
+
+	for i, action := range actions {
+		decision, decided, ok := c.cache.getAt(decisionKey{subject, action.Action, action.ResourceID}, now)
+		if !ok {
+			return nil, 0, false
+		}
+
+		allowed[i] = decision
+		age = max(age, now.Sub(decided))
+	}
+
+	return allowed, age, true
+}
@@ -0,0 +1,189 @@
+package permissions
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionCacheTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	cache := newDecisionCache(CacheConfig{
+		AllowTTL: 30 * time.Second,
+		DenyTTL:  10 * time.Second,
+	})
+
+	cache.now = func() time.Time { return now }
+
+	subject := hashSubject("token")
+
+	allowKey := decisionKey{subject, "read", "resource-1"}
+	denyKey := decisionKey{subject, "write", "resource-1"}
+
+	cache.set(allowKey, true)
+	cache.set(denyKey, false)
+
+	testCases := []struct {
+		name          string
+		elapsed       time.Duration
+		key           decisionKey
+		expectFound   bool
+		expectAllowed bool
+	}{
+		{"allow cached", 0, allowKey, true, true},
+		{"deny cached", 0, denyKey, true, false},
+		{"deny expired", 10 * time.Second, denyKey, false, false},
+		{"allow still cached", 20 * time.Second, allowKey, true, true},
+		{"allow expired", 30 * time.Second, allowKey, false, false},
+		{"other subject", 0, decisionKey{hashSubject("other"), "read", "resource-1"}, false, false},
+	}
+
+	for _, tc := range testCases {
+		cache.now = func() time.Time { return now.Add(tc.elapsed) }
+
+		allowed, found := cache.get(tc.key)
+
+		assert.Equal(t, tc.expectFound, found, "%s: unexpected found result", tc.name)
+		assert.Equal(t, tc.expectAllowed, allowed, "%s: unexpected allowed result", tc.name)
+	}
+}
+
+func TestDecisionCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	cache := newDecisionCache(CacheConfig{
+		AllowTTL:   time.Minute,
+		MaxEntries: 3,
+	})
+
+	subject := hashSubject("token")
+
+	key := func(i int) decisionKey {
+		return decisionKey{subject, "read", "resource-" + strconv.Itoa(i)}
+	}
+
+	for i := 0; i < 3; i++ {
+		cache.set(key(i), true)
+	}
+
+	// Touch the oldest entry so it is no longer the least recently used.
+	_, found := cache.get(key(0))
+	require.True(t, found, "expected entry to be cached")
+
+	cache.set(key(3), true)
+
+	assert.Equal(t, 3, cache.len(), "unexpected cache size")
+
+	_, found = cache.get(key(1))
+	assert.False(t, found, "expected least recently used entry to be evicted")
+
+	for _, i := range []int{0, 2, 3} {
+		_, found = cache.get(key(i))
+		assert.True(t, found, "expected entry %d to remain cached", i)
+	}
+}
+
+func TestDecisionCacheLookup(t *testing.T) {
+	t.Parallel()
+
+	subject := hashSubject("token")
+
+	read := RequestAction{Action: "read", ResourceID: "resource-1"}
+	write := RequestAction{Action: "write", ResourceID: "resource-1"}
+	remove := RequestAction{Action: "delete", ResourceID: "resource-1"}
+
+	testCases := []struct {
+		name          string
+		stored        map[RequestAction]bool
+		actions       []RequestAction
+		expectDecided bool
+		expectDenied  bool
+		expectHits    int
+		expectMisses  int
+	}{
+		{"empty request", nil, nil, false, false, 0, 0},
+		{"all miss", nil, []RequestAction{read, write}, false, false, 0, 2},
+		{"all allowed", map[RequestAction]bool{read: true, write: true}, []RequestAction{read, write}, true, false, 2, 0},
+		{"partial allowed", map[RequestAction]bool{read: true}, []RequestAction{read, write}, false, false, 1, 1},
+		{"one denied", map[RequestAction]bool{remove: false}, []RequestAction{read, remove}, true, true, 1, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := newDecisionCache(CacheConfig{
+				AllowTTL: time.Minute,
+				DenyTTL:  time.Minute,
+			})
+
+			for action, allowed := range tc.stored {
+				cache.set(decisionKey{subject, action.Action, action.ResourceID}, allowed)
+			}
+
+			result := cache.lookup(subject, tc.actions)
+
+			assert.Equal(t, tc.expectDecided, result.decided(), "unexpected decided result")
+			assert.Equal(t, tc.expectDenied, result.denied, "unexpected denied result")
+			assert.Equal(t, tc.expectHits, result.hits, "unexpected hits")
+			assert.Equal(t, tc.expectMisses, result.misses, "unexpected misses")
+		})
+	}
+}
+
+func TestDecisionCacheStore(t *testing.T) {
+	t.Parallel()
+
+	subject := hashSubject("token")
+
+	read := RequestAction{Action: "read", ResourceID: "resource-1"}
+	write := RequestAction{Action: "write", ResourceID: "resource-1"}
+
+	cache := newDecisionCache(CacheConfig{
+		AllowTTL: time.Minute,
+		DenyTTL:  time.Minute,
+	})
+
+	// A denial for multiple actions can't be attributed to a single action and must not be cached.
+	cache.store(subject, []RequestAction{read, write}, false)
+	assert.Equal(t, 0, cache.len(), "expected multi-action denial to not be cached")
+
+	cache.store(subject, []RequestAction{write}, false)
+	assert.Equal(t, 1, cache.len(), "expected single action denial to be cached")
+
+	cache.store(subject, []RequestAction{read}, true)
+
+	result := cache.lookup(subject, []RequestAction{read, write})
+
+	assert.True(t, result.decided(), "expected decision")
+	assert.True(t, result.denied, "expected denial")
+}
+
+func TestStaleCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	actions := []RequestAction{{Action: "read", ResourceID: "tnntten-abc"}, {Action: "write", ResourceID: "tnntten-abc"}}
+
+	cache := NewStaleCache(10)
+	cache.Store("token", actions, []bool{true, false}, now, now.Add(time.Minute))
+	cache.Store("token", actions[:1], []bool{true}, now.Add(10*time.Second), now.Add(time.Minute))
+
+	allowed, age, ok := cache.Load("token", actions, now.Add(30*time.Second))
+	require.True(t, ok, "expected decisions to be loaded")
+	assert.Equal(t, []bool{true, false}, allowed, "unexpected decisions")
+	assert.Equal(t, 30*time.Second, age, "expected age of the oldest decision")
+
+	_, _, ok = cache.Load("other-token", actions, now)
+	assert.False(t, ok, "expected decisions to not be shared between credentials")
+
+	_, _, ok = cache.Load("token", actions, now.Add(time.Minute))
+	assert.False(t, ok, "expected expired decisions to not be loaded")
+}
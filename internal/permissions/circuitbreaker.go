@@ -0,0 +1,240 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 30 * time.Second
+	defaultCircuitHalfOpenProbes   = 1
+)
+
+// circuit tracks the state of the circuit of a single host.
+type circuit struct {
+	state     string
+	failures  int
+	successes int
+	openedAt  time.Time
+	probing   bool
+}
+
+// circuitBreakers is a round tripper which tracks a circuit for each request host. Once the
+// failure threshold of consecutive failed requests to a host is reached, the host's circuit opens
+// and requests to it fail immediately with ErrCircuitOpen. After the open duration a single probe
+// request at a time is allowed through, and the circuit closes once enough probes succeed.
+//
+// Used below a [selecthost.Transport], a request failing on an open circuit marks the selected
+// host as failed so another host is selected for the next attempt. Circuits of hosts no longer
+// returned by discovery are removed.
+type circuitBreakers struct {
+	base   http.RoundTripper
+	logger *zap.SugaredLogger
+
+	failureThreshold int
+	openDuration     time.Duration
+	halfOpenProbes   int
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+
+	now func() time.Time
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig, base http.RoundTripper, logger *zap.SugaredLogger) *circuitBreakers {
+	config = config.withDefaults()
+
+	return &circuitBreakers{
+		base:             base,
+		logger:           logger,
+		failureThreshold: config.FailureThreshold,
+		openDuration:     config.OpenDuration,
+		halfOpenProbes:   config.HalfOpenProbes,
+		circuits:         make(map[string]*circuit),
+		now:              time.Now,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (b *circuitBreakers) RoundTrip(r *http.Request) (*http.Response, error) {
+	span := trace.SpanFromContext(r.Context())
+
+	host := r.URL.Host
+
+	state, allowed := b.allow(host)
+
+	span.SetAttributes(
+		attribute.String("permissions.circuit.host", host),
+		attribute.String("permissions.circuit.state", state),
+	)
+
+	if !allowed {
+		span.AddEvent("circuit open")
+
+		return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+	}
+
+	resp, err := b.base.RoundTrip(r)
+
+	b.record(r.Context(), host, err == nil && resp.StatusCode < http.StatusInternalServerError)
+
+	return resp, err
+}
+
+// allow returns the state of the host's circuit and whether a request may be made to the host.
+func (b *circuitBreakers) allow(host string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{state: circuitClosed}
+
+		b.circuits[host] = c
+	}
+
+	switch c.state {
+	case circuitOpen:
+		if b.now().Sub(c.openedAt) < b.openDuration {
+			return c.state, false
+		}
+
+		b.logger.Infow("permissions-api circuit half-open, probing host", "host", host)
+
+		c.state = circuitHalfOpen
+		c.successes = 0
+		c.probing = true
+
+		return c.state, true
+	case circuitHalfOpen:
+		if c.probing {
+			return c.state, false
+		}
+
+		c.probing = true
+
+		return c.state, true
+	default:
+		return c.state, true
+	}
+}
+
+// record records the result of a request to the host. Requests canceled by the caller are not
+// counted as failures, while requests ended by the attempt timeout are.
+func (b *circuitBreakers) record(ctx context.Context, host string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[host]
+	if !ok {
+		return
+	}
+
+	if !success && selecthost.RequestorDone(ctx) {
+		c.probing = false
+
+		return
+	}
+
+	switch c.state {
+	case circuitClosed:
+		if success {
+			c.failures = 0
+
+			return
+		}
+
+		c.failures++
+
+		if c.failures >= b.failureThreshold {
+			b.open(host, c)
+		}
+	case circuitHalfOpen:
+		c.probing = false
+
+		if !success {
+			b.open(host, c)
+
+			return
+		}
+
+		c.successes++
+
+		if c.successes >= b.halfOpenProbes {
+			b.logger.Infow("permissions-api circuit closed", "host", host)
+
+			c.state = circuitClosed
+			c.failures = 0
+		}
+	}
+}
+
+// open opens the host's circuit. The lock must be held.
+func (b *circuitBreakers) open(host string, c *circuit) {
+	b.logger.Warnw("permissions-api circuit opened, failing requests to host",
+		"host", host,
+		"open_duration", b.openDuration,
+	)
+
+	c.state = circuitOpen
+	c.openedAt = b.now()
+	c.probing = false
+}
+
+// remove removes the circuits of a host no longer returned by discovery.
+func (b *circuitBreakers) remove(host selecthost.Host) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for addr := range b.circuits {
+		name, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			name = addr
+		}
+
+		if name == host.Host() {
+			delete(b.circuits, addr)
+		}
+	}
+}
+
+// HealthCheck returns ErrCircuitOpen when the circuit of every known host is open.
+func (b *circuitBreakers) HealthCheck(_ context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	open := make([]string, 0, len(b.circuits))
+
+	for host, c := range b.circuits {
+		if c.state != circuitOpen {
+			return nil
+		}
+
+		open = append(open, host)
+	}
+
+	if len(open) == 0 {
+		return nil
+	}
+
+	slices.Sort(open)
+
+	return fmt.Errorf("%w: %s", ErrCircuitOpen, strings.Join(open, ", "))
+}
@@ -0,0 +1,260 @@
+package permissions
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+func TestCircuitBreakers(t *testing.T) {
+	t.Parallel()
+
+	var (
+		status   atomic.Int32
+		requests atomic.Int32
+	)
+
+	status.Store(http.StatusServiceUnavailable)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(srv.Close)
+
+	now := time.Now()
+
+	breakers := newCircuitBreakers(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		HalfOpenProbes:   2,
+	}, http.DefaultTransport, zap.NewNop().Sugar())
+
+	breakers.now = func() time.Time { return now }
+
+	client := &http.Client{Transport: breakers}
+
+	do := func() error {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+		require.NoError(t, err, "no error expected creating request")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		return resp.Body.Close()
+	}
+
+	require.NoError(t, do(), "expected failed response to be returned while closed")
+	assert.NoError(t, breakers.HealthCheck(context.Background()), "expected circuit to not be open before failure threshold")
+	require.NoError(t, do(), "expected failed response to be returned while closed")
+
+	assert.ErrorIs(t, do(), ErrCircuitOpen, "expected circuit to open at the failure threshold")
+	assert.Equal(t, int32(2), requests.Load(), "expected open circuit to not make requests")
+	assert.ErrorIs(t, breakers.HealthCheck(context.Background()), ErrCircuitOpen, "expected health check to report open circuit")
+
+	now = now.Add(2 * time.Minute)
+
+	require.NoError(t, do(), "expected probe request after open duration")
+	assert.Equal(t, int32(3), requests.Load(), "expected probe request to be made")
+	assert.ErrorIs(t, do(), ErrCircuitOpen, "expected failed probe to reopen circuit")
+
+	now = now.Add(2 * time.Minute)
+	status.Store(http.StatusOK)
+
+	require.NoError(t, do(), "expected probe request after open duration")
+	require.NoError(t, do(), "expected second probe request")
+	assert.NoError(t, breakers.HealthCheck(context.Background()), "expected circuit to close after successful probes")
+
+	require.NoError(t, do(), "expected requests while closed")
+	assert.Equal(t, int32(6), requests.Load(), "unexpected request count")
+}
+
+func TestCircuitBreakersHalfOpenSingleProbe(t *testing.T) {
+	t.Parallel()
+
+	breakers := newCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 1}, http.DefaultTransport, zap.NewNop().Sugar())
+
+	now := time.Now()
+	breakers.now = func() time.Time { return now }
+
+	_, allowed := breakers.allow("host")
+	require.True(t, allowed, "expected request to be allowed while closed")
+
+	breakers.record(context.Background(), "host", false)
+
+	state, allowed := breakers.allow("host")
+	assert.Equal(t, circuitOpen, state, "expected circuit to be open")
+	assert.False(t, allowed, "expected request to not be allowed")
+
+	now = now.Add(defaultCircuitOpenDuration)
+
+	state, allowed = breakers.allow("host")
+	assert.Equal(t, circuitHalfOpen, state, "expected circuit to be half-open")
+	assert.True(t, allowed, "expected probe request to be allowed")
+
+	_, allowed = breakers.allow("host")
+	assert.False(t, allowed, "expected only a single probe request at a time")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	breakers.record(ctx, "host", false)
+
+	state, allowed = breakers.allow("host")
+	assert.Equal(t, circuitHalfOpen, state, "expected canceled probe to not reopen the circuit")
+	assert.True(t, allowed, "expected another probe once the canceled probe completes")
+}
+
+func TestCircuitBreakersTimeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		callerTimeout time.Duration
+		expectOpen    bool
+	}{
+		{"attempt timeout", 0, true},
+		{"caller deadline", 20 * time.Millisecond, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			}))
+			t.Cleanup(srv.Close)
+
+			breakers := newCircuitBreakers(CircuitBreakerConfig{FailureThreshold: 2}, http.DefaultTransport, zap.NewNop().Sugar())
+
+			client := &http.Client{
+				Transport: &attemptTimeout{base: breakers, timeout: 50 * time.Millisecond},
+			}
+
+			for range 2 {
+				ctx := context.Background()
+
+				if tc.callerTimeout > 0 {
+					var cancel context.CancelFunc
+
+					ctx, cancel = context.WithTimeout(ctx, tc.callerTimeout)
+					defer cancel()
+				}
+
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+				require.NoError(t, err, "no error expected creating request")
+
+				resp, err := client.Do(req)
+				if err == nil {
+					resp.Body.Close() //nolint:errcheck // error check not needed
+				}
+
+				require.Error(t, err, "expected request to time out")
+			}
+
+			err := breakers.HealthCheck(context.Background())
+
+			if tc.expectOpen {
+				assert.ErrorIs(t, err, ErrCircuitOpen, "expected hanging host to open the circuit")
+
+				return
+			}
+
+			assert.NoError(t, err, "expected caller deadline to not open the circuit")
+		})
+	}
+}
+
+func TestCircuitBreakersHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		circuits  map[string]string
+		expectErr error
+	}{
+		{
+			name: "no circuits",
+		},
+		{
+			name: "some open",
+			circuits: map[string]string{
+				"a.example.com": circuitOpen,
+				"b.example.com": circuitClosed,
+			},
+		},
+		{
+			name: "half-open",
+			circuits: map[string]string{
+				"a.example.com": circuitOpen,
+				"b.example.com": circuitHalfOpen,
+			},
+		},
+		{
+			name: "all open",
+			circuits: map[string]string{
+				"a.example.com": circuitOpen,
+				"b.example.com": circuitOpen,
+			},
+			expectErr: ErrCircuitOpen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			breakers := newCircuitBreakers(CircuitBreakerConfig{}, http.DefaultTransport, zap.NewNop().Sugar())
+
+			for host, state := range tc.circuits {
+				breakers.circuits[host] = &circuit{state: state}
+			}
+
+			err := breakers.HealthCheck(context.Background())
+
+			if tc.expectErr != nil {
+				assert.ErrorIs(t, err, tc.expectErr, "unexpected error")
+
+				return
+			}
+
+			assert.NoError(t, err, "no error expected")
+		})
+	}
+}
+
+func TestCircuitBreakersRemove(t *testing.T) {
+	t.Parallel()
+
+	breakers := newCircuitBreakers(CircuitBreakerConfig{}, http.DefaultTransport, zap.NewNop().Sugar())
+
+	for _, addr := range []string{"a.example.com", "a.example.com:8443", "b.example.com:8443"} {
+		breakers.circuits[addr] = &circuit{state: circuitOpen}
+	}
+
+	host, err := selecthost.ParseHost(nil, "a.example.com:8443")
+	require.NoError(t, err, "no error expected parsing host")
+
+	breakers.remove(host)
+
+	assert.Equal(t, []string{"b.example.com:8443"}, slices.Collect(maps.Keys(breakers.circuits)), "expected circuits of removed host to be removed")
+
+	breakers.record(context.Background(), "a.example.com", false)
+
+	assert.Len(t, breakers.circuits, 1, "expected in-flight request to a removed host to not recreate its circuit")
+}
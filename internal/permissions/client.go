@@ -3,13 +3,13 @@ package permissions
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"go.opentelemetry.io/otel"
@@ -17,7 +17,9 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
 	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
 )
 
@@ -37,7 +39,9 @@ const (
 
 	tracerName = "go.infratographer.com/iam-runtime-infratographer/internal/permissions"
 
-	clientTimeout = 5 * time.Second
+	defaultCacheMaxEntries = 10000
+
+	defaultPerActionConcurrency = 5
 )
 
 // RequestAction represents an (action, resource) pair to check access to in a request.
@@ -54,8 +58,21 @@ type checkPermissionRequest struct {
 type Client interface {
 	CheckAccess(ctx context.Context, subjToken string, actions []RequestAction) error
 
+	// CheckActions evaluates each action individually, returning a result for every action.
+	CheckActions(ctx context.Context, subjToken string, actions []RequestAction) ([]ActionResult, error)
+
 	// HealthCheck returns nil when the service is healthy.
 	HealthCheck(ctx context.Context) error
+
+	// Reconfigure applies the settings which can be changed while running, the preferred and
+	// fallback discovery hosts.
+	Reconfigure(config Config) error
+
+	// Close stops host discovery and unregisters its metrics.
+	Close()
+
+	// CircuitHealthCheck returns nil unless the circuit of every known permissions-api host is open.
+	CircuitHealthCheck(ctx context.Context) error
 }
 
 type client struct {
@@ -63,8 +80,15 @@ type client struct {
 	apiURL         string
 	healthCheckURL string
 	httpClient     *retryablehttp.Client
+	selector       *selecthost.Selector
+	unregister     func()
+	breakers       *circuitBreakers
+	cache          *decisionCache
+	inflight       singleflight.Group
 	tracer         trace.Tracer
 	logger         *zap.SugaredLogger
+
+	perActionConcurrency int
 }
 
 // NewClient creates a new permissions-api client.
@@ -83,28 +107,75 @@ func NewClient(config Config, logger *zap.SugaredLogger) (Client, error) {
 		return nil, err
 	}
 
-	transport, err := config.initTransport(http.DefaultTransport, selecthost.Logger(logger))
+	var (
+		base     = http.DefaultTransport
+		breakers *circuitBreakers
+	)
+
+	if config.CircuitBreaker.Enabled {
+		breakers = newCircuitBreakers(config.CircuitBreaker, base, logger)
+		base = breakers
+	}
+
+	retry := config.Retry.withDefaults()
+	policy := newRetryPolicy(retry, !config.Discovery.Disable)
+
+	opts := []selecthost.Option{
+		selecthost.Logger(logger),
+		selecthost.AllowFailover(policy.allowFailover),
+	}
+
+	if breakers != nil {
+		opts = append(opts, selecthost.OnRemove(breakers.remove))
+	}
+
+	transport, selector, err := config.initTransport(base, opts...)
 	if err != nil {
 		return nil, err
 	}
 
+	var unregister func()
+
+	if selector != nil {
+		if unregister, err = metrics.RegisterSelector(selector); err != nil {
+			selector.Stop()
+
+			return nil, fmt.Errorf("failed to register selector metrics: %w", err)
+		}
+	}
+
 	httpClient := retryablehttp.NewClient()
 
-	httpClient.RetryWaitMin = 100 * time.Millisecond //nolint:mnd
-	httpClient.RetryWaitMax = 2 * time.Second        //nolint:mnd
 	httpClient.Logger = &retryableLogger{logger}
 	httpClient.HTTPClient = &http.Client{
-		Timeout:   clientTimeout,
-		Transport: transport,
+		Transport: &attemptTimeout{
+			base:    metrics.InstrumentPermissionsTransport(transport),
+			timeout: retry.AttemptTimeout,
+		},
 	}
 
+	policy.configure(httpClient)
+
 	out := &client{
 		enabled:        true,
 		apiURL:         apiURLString,
 		healthCheckURL: fmt.Sprintf("https://%s%s", config.Host, healthCheckRoute),
 		httpClient:     httpClient,
+		selector:       selector,
+		unregister:     unregister,
+		breakers:       breakers,
 		tracer:         otel.GetTracerProvider().Tracer(tracerName),
 		logger:         logger,
+
+		perActionConcurrency: config.PerActionConcurrency,
+	}
+
+	if out.perActionConcurrency <= 0 {
+		out.perActionConcurrency = defaultPerActionConcurrency
+	}
+
+	if config.Cache.Enabled {
+		out.cache = newDecisionCache(config.Cache)
 	}
 
 	return out, nil
@@ -118,6 +189,10 @@ func checkResponse(resp *http.Response) error {
 		case http.StatusForbidden:
 			return ErrPermissionDenied
 		default:
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("%w: %w: status code %d", ErrUnexpectedResponse, ErrUnavailable, resp.StatusCode)
+			}
+
 			return fmt.Errorf("%w: status code %d", ErrUnexpectedResponse, resp.StatusCode)
 		}
 	}
@@ -136,6 +211,67 @@ func (c *client) CheckAccess(ctx context.Context, subjToken string, actions []Re
 		return ErrServiceDisabled
 	}
 
+	var subject [sha256.Size]byte
+
+	if c.cache != nil {
+		subject = hashSubject(subjToken)
+
+		lookup := c.cache.lookup(subject, actions)
+
+		span.SetAttributes(
+			attribute.Bool("permissions.cache.hit", lookup.decided()),
+			attribute.Int("permissions.cache.hits", lookup.hits),
+			attribute.Int("permissions.cache.misses", lookup.misses),
+		)
+
+		if lookup.decided() {
+			var err error
+
+			if lookup.denied {
+				err = ErrPermissionDenied
+			}
+
+			setOutcome(span, err)
+
+			return err
+		}
+	}
+
+	err := c.coalescedRequestAccess(ctx, subjToken, actions)
+
+	setOutcome(span, err)
+
+	if c.cache != nil {
+		switch {
+		case err == nil:
+			c.cache.store(subject, actions, true)
+		case errors.Is(err, ErrPermissionDenied):
+			c.cache.store(subject, actions, false)
+		}
+	}
+
+	return err
+}
+
+// setOutcome records the outcome of a CheckAccess call on the provided span.
+func setOutcome(span trace.Span, err error) {
+	switch {
+	case err == nil:
+		span.SetAttributes(attribute.String("permissions.outcome", outcomeAllowed))
+	case errors.Is(err, ErrPermissionDenied):
+		span.SetAttributes(attribute.String("permissions.outcome", outcomeDenied))
+	default:
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// requestAccess sends the check request to permissions-api.
+func (c *client) requestAccess(ctx context.Context, subjToken string, actions []RequestAction) error {
+	ctx, span := c.tracer.Start(ctx, "requestAccess", trace.WithAttributes(
+		attribute.Int("permissions.actions", len(actions)),
+	))
+	defer span.End()
+
 	request := checkPermissionRequest{
 		Actions: actions,
 	}
@@ -151,7 +287,7 @@ func (c *client) CheckAccess(ctx context.Context, subjToken string, actions []Re
 	}
 
 	// Build the request to send up to permissions-api.
-	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, c.apiURL, &reqBody)
+	req, err := retryablehttp.NewRequestWithContext(withRetryAttempts(ctx), http.MethodPost, c.apiURL, &reqBody)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		c.logger.Errorw("failed to create permissions-api request", "error", err)
@@ -171,7 +307,7 @@ func (c *client) CheckAccess(ctx context.Context, subjToken string, actions []Re
 		span.SetStatus(codes.Error, err.Error())
 		c.logger.Errorw("failed to make permissions-api request", "error", err)
 
-		return err
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
 	}
 
 	defer resp.Body.Close() //nolint:errcheck
@@ -260,3 +396,38 @@ func (c *client) HealthCheck(ctx context.Context) error {
 
 	return nil
 }
+
+// Reconfigure applies the settings which can be changed while running, the preferred and
+// fallback discovery hosts.
+func (c *client) Reconfigure(config Config) error {
+	if c.selector == nil {
+		return nil
+	}
+
+	fallback := config.Discovery.Fallback
+	if fallback == "" {
+		fallback = config.Host
+	}
+
+	return c.selector.UpdateHosts(config.Discovery.Prefer, fallback)
+}
+
+// Close stops host discovery and unregisters its metrics.
+func (c *client) Close() {
+	if c.selector != nil {
+		c.selector.Stop()
+	}
+
+	if c.unregister != nil {
+		c.unregister()
+	}
+}
+
+// CircuitHealthCheck returns nil unless the circuit of every known permissions-api host is open.
+func (c *client) CircuitHealthCheck(ctx context.Context) error {
+	if c.breakers == nil {
+		return nil
+	}
+
+	return c.breakers.HealthCheck(ctx)
+}
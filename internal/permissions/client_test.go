@@ -0,0 +1,45 @@
+package permissions
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckResponse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		status            int
+		expectError       error
+		expectUnavailable bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"unauthorized", http.StatusUnauthorized, ErrUnauthenticated, false},
+		{"forbidden", http.StatusForbidden, ErrPermissionDenied, false},
+		{"bad request", http.StatusBadRequest, ErrUnexpectedResponse, false},
+		{"too many requests", http.StatusTooManyRequests, ErrUnexpectedResponse, false},
+		{"internal server error", http.StatusInternalServerError, ErrUnexpectedResponse, true},
+		{"service unavailable", http.StatusServiceUnavailable, ErrUnexpectedResponse, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkResponse(&http.Response{StatusCode: tc.status})
+
+			if tc.expectError == nil {
+				assert.NoError(t, err, "no error expected")
+
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+			assert.Equal(t, tc.expectUnavailable, errors.Is(err, ErrUnavailable), "unexpected unavailable error")
+		})
+	}
+}
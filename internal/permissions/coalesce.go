@@ -0,0 +1,86 @@
+package permissions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxCoalescedReissues limits how often a caller issues a shared request again after it was ended
+// by the deadline of another caller.
+const maxCoalescedReissues = 2
+
+// coalesceKey builds the key identifying identical in-flight requests.
+// Requests are identical when both the credential and the ordered list of actions match.
+func coalesceKey(subjToken string, actions []RequestAction) string {
+	hash := sha256.New()
+
+	hash.Write([]byte(subjToken))
+
+	for _, action := range actions {
+		hash.Write([]byte{0})
+		hash.Write([]byte(action.Action))
+		hash.Write([]byte{0})
+		hash.Write([]byte(action.ResourceID))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// coalescedRequestAccess sends the check request to permissions-api, sharing a single upstream
+// request between all concurrent callers checking the same credential and actions.
+// The result, including any error, is returned to every caller. If the shared request was
+// ended by the deadline of the caller which started it, callers with time remaining issue the
+// request again, up to maxCoalescedReissues times.
+func (c *client) coalescedRequestAccess(ctx context.Context, subjToken string, actions []RequestAction) error {
+	span := trace.SpanFromContext(ctx)
+
+	key := coalesceKey(subjToken, actions)
+
+	for reissued := 0; ; reissued++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-c.inflight.DoChan(key, c.sharedRequestAccess(ctx, subjToken, actions)):
+			span.SetAttributes(attribute.Bool("permissions.coalesced", result.Shared))
+
+			leaderExpired, _ := result.Val.(bool)
+
+			if result.Shared && leaderExpired && result.Err != nil && ctx.Err() == nil && reissued < maxCoalescedReissues {
+				span.AddEvent("shared request ended by another caller's deadline")
+
+				continue
+			}
+
+			return result.Err
+		}
+	}
+}
+
+// sharedRequestAccess returns the function sending a request shared with other callers.
+// The shared request must not be canceled when the caller which started it goes away,
+// as other callers may still be waiting on the result. It is still bounded by that caller's
+// deadline, so time spent retrying never exceeds it. The function's value reports whether
+// that deadline passed, telling it apart from the request's own attempt timeouts.
+func (c *client) sharedRequestAccess(ctx context.Context, subjToken string, actions []RequestAction) func() (any, error) {
+	sharedCtx := context.WithoutCancel(ctx)
+	deadline, hasDeadline := ctx.Deadline()
+
+	return func() (any, error) {
+		reqCtx := sharedCtx
+
+		if hasDeadline {
+			var cancel context.CancelFunc
+
+			reqCtx, cancel = context.WithDeadline(sharedCtx, deadline)
+			defer cancel()
+		}
+
+		err := c.requestAccess(reqCtx, subjToken, actions)
+
+		return reqCtx.Err() != nil, err
+	}
+}
@@ -0,0 +1,277 @@
+package permissions
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *client {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+
+	t.Cleanup(srv.Close)
+
+	httpClient := retryablehttp.NewClient()
+	httpClient.RetryMax = 0
+	httpClient.Logger = nil
+
+	return &client{
+		enabled:    true,
+		apiURL:     srv.URL + apiRoute,
+		httpClient: httpClient,
+		tracer:     otel.GetTracerProvider().Tracer(tracerName),
+		logger:     zap.NewNop().Sugar(),
+	}
+}
+
+func TestCoalesceKey(t *testing.T) {
+	t.Parallel()
+
+	read := RequestAction{Action: "read", ResourceID: "resource-1"}
+	write := RequestAction{Action: "write", ResourceID: "resource-1"}
+
+	key := coalesceKey("token", []RequestAction{read, write})
+
+	assert.Equal(t, key, coalesceKey("token", []RequestAction{read, write}), "expected identical requests to match")
+	assert.NotEqual(t, key, coalesceKey("other", []RequestAction{read, write}), "expected different credentials to not match")
+	assert.NotEqual(t, key, coalesceKey("token", []RequestAction{write, read}), "expected different actions to not match")
+	assert.NotEqual(t,
+		coalesceKey("token", []RequestAction{{Action: "ab", ResourceID: "c"}}),
+		coalesceKey("token", []RequestAction{{Action: "a", ResourceID: "bc"}}),
+		"expected field boundaries to be respected",
+	)
+}
+
+func TestCheckAccessCoalesced(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		statusCode   int
+		expectError  error
+		callerCount  int
+		expectCalled int32
+	}{
+		{"allowed", http.StatusOK, nil, 10, 1},
+		{"denied", http.StatusForbidden, ErrPermissionDenied, 10, 1},
+		{"unauthenticated", http.StatusUnauthorized, ErrUnauthenticated, 10, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				called  atomic.Int32
+				release = make(chan struct{})
+			)
+
+			c := testClient(t, func(w http.ResponseWriter, _ *http.Request) {
+				called.Add(1)
+
+				<-release
+
+				w.WriteHeader(tc.statusCode)
+			})
+
+			actions := []RequestAction{{Action: "read", ResourceID: "resource-1"}}
+
+			var wg sync.WaitGroup
+
+			errs := make([]error, tc.callerCount)
+
+			for i := 0; i < tc.callerCount; i++ {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					errs[i] = c.CheckAccess(context.Background(), "token", actions)
+				}()
+			}
+
+			// Give all callers a chance to join the in-flight request.
+			time.Sleep(50 * time.Millisecond)
+
+			close(release)
+
+			wg.Wait()
+
+			assert.Equal(t, tc.expectCalled, called.Load(), "unexpected number of upstream requests")
+
+			for i, err := range errs {
+				if tc.expectError == nil {
+					assert.NoError(t, err, "caller %d: no error expected", i)
+				} else {
+					assert.ErrorIs(t, err, tc.expectError, "caller %d: unexpected error", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAccessCoalescedCallerCanceled(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	c := testClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	actions := []RequestAction{{Action: "read", ResourceID: "resource-1"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	firstErr := make(chan error, 1)
+
+	go func() {
+		firstErr <- c.CheckAccess(ctx, "token", actions)
+	}()
+
+	secondErr := make(chan error, 1)
+
+	go func() {
+		secondErr <- c.CheckAccess(context.Background(), "token", actions)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Canceling the first caller must not cancel the shared request for the second caller.
+	cancel()
+
+	assert.ErrorIs(t, <-firstErr, context.Canceled, "expected canceled caller to return context error")
+
+	close(release)
+
+	assert.NoError(t, <-secondErr, "expected remaining caller to receive shared result")
+}
+
+func TestCheckAccessCoalescedDeadline(t *testing.T) {
+	t.Parallel()
+
+	requestDone := make(chan struct{})
+
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		defer close(requestDone)
+
+		// Cancellation is only detected once the request body has been read.
+		io.ReadAll(r.Body) //nolint:errcheck // error check not needed
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	actions := []RequestAction{{Action: "read", ResourceID: "resource-1"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, c.CheckAccess(ctx, "token", actions), context.DeadlineExceeded, "expected caller deadline to be exceeded")
+
+	// The shared request is bounded by the deadline of the caller which started it.
+	select {
+	case <-requestDone:
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected shared request to be canceled at the caller's deadline")
+	}
+}
+
+func TestCheckAccessCoalescedFollowerDeadline(t *testing.T) {
+	t.Parallel()
+
+	var called atomic.Int32
+
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body) //nolint:errcheck // error check not needed
+
+		// The first request outlives the deadline of the caller which started it.
+		if called.Add(1) == 1 {
+			<-r.Context().Done()
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	actions := []RequestAction{{Action: "read", ResourceID: "resource-1"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	leaderErr := make(chan error, 1)
+
+	go func() {
+		leaderErr <- c.CheckAccess(ctx, "token", actions)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	followerCtx, followerCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer followerCancel()
+
+	assert.NoError(t, c.CheckAccess(followerCtx, "token", actions), "expected follower to issue the request again")
+	assert.ErrorIs(t, <-leaderErr, context.DeadlineExceeded, "expected leader deadline to be exceeded")
+	assert.Equal(t, int32(2), called.Load(), "expected request to be issued again")
+}
+
+func TestCheckAccessCoalescedAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	var called atomic.Int32
+
+	c := testClient(t, func(_ http.ResponseWriter, r *http.Request) {
+		called.Add(1)
+
+		io.ReadAll(r.Body) //nolint:errcheck // error check not needed
+
+		<-r.Context().Done()
+	})
+
+	c.httpClient.HTTPClient.Transport = &attemptTimeout{base: http.DefaultTransport, timeout: 50 * time.Millisecond}
+
+	actions := []RequestAction{{Action: "read", ResourceID: "resource-1"}}
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 5)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// Callers without a deadline must not issue a request ended by an attempt timeout again.
+			errs[i] = c.CheckAccess(context.Background(), "token", actions)
+		}()
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.ErrorIs(t, err, selecthost.ErrAttemptTimeout, "caller %d: unexpected error", i)
+	}
+
+	assert.Equal(t, int32(1), called.Load(), "expected timed out request to not be issued again")
+}
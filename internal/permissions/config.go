@@ -1,7 +1,9 @@
 package permissions
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -20,13 +22,28 @@ type Config struct {
 
 	// Discovery defines the host discovery configuration.
 	Discovery DiscoveryConfig
+
+	// Cache defines the decision cache configuration.
+	Cache CacheConfig
+
+	// CircuitBreaker defines the per-host circuit breaker configuration.
+	CircuitBreaker CircuitBreakerConfig
+
+	// Retry defines the request retry configuration.
+	Retry RetryConfig
+
+	// PerActionConcurrency limits the number of concurrent requests made to permissions-api
+	// when evaluating actions individually.
+	//
+	// Default: 5
+	PerActionConcurrency int
 }
 
-func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option) (http.RoundTripper, error) {
+func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option) (http.RoundTripper, *selecthost.Selector, error) {
 	base = otelhttp.NewTransport(base)
 
 	if c.Disable || c.Discovery.Disable {
-		return base, nil
+		return base, nil, nil
 	}
 
 	cOpts := []selecthost.Option{
@@ -47,6 +64,14 @@ func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option)
 		cOpts = append(cOpts, selecthost.Optional())
 	}
 
+	if discovery.Failover != nil {
+		cOpts = append(cOpts, selecthost.Failover(*discovery.Failover))
+	}
+
+	if discovery.Balancing != "" {
+		cOpts = append(cOpts, selecthost.Balance(selecthost.Balancing(discovery.Balancing)))
+	}
+
 	if discovery.Prefer != "" {
 		cOpts = append(cOpts, selecthost.Prefer(discovery.Prefer))
 	}
@@ -67,6 +92,15 @@ func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option)
 		cOpts = append(cOpts, selecthost.CheckPath("/readyz"))
 	}
 
+	if len(check.ExpectStatus) != 0 || len(check.ExpectHeaders) != 0 || check.ExpectBody != "" {
+		checker, err := check.httpChecker()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cOpts = append(cOpts, selecthost.CheckWith(checker))
+	}
+
 	if check.Count > 0 {
 		cOpts = append(cOpts, selecthost.CheckCount(check.Count))
 	}
@@ -89,12 +123,12 @@ func (c Config) initTransport(base http.RoundTripper, opts ...selecthost.Option)
 
 	selector, err := selecthost.NewSelector(c.Host, "permissions-api", "tcp", append(cOpts, opts...)...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	selector.Start()
 
-	return selecthost.NewTransport(selector, base), nil
+	return selecthost.NewTransport(selector, base), selector, nil
 }
 
 // DiscoveryConfig represents the host discovery configuration.
@@ -125,6 +159,20 @@ type DiscoveryConfig struct {
 	// Check customizes the target health checking process.
 	Check CheckConfig
 
+	// Failover sets the number of other hosts a request is retried on when the selected host fails
+	// with a connection error or a 5xx response. Zero disables failover. Each failover counts as a
+	// retry against the request's retries and the retry budget.
+	//
+	// Default: 2
+	Failover *int
+
+	// Balancing sets how requests are distributed across the healthy discovered hosts of the
+	// lowest priority. One of none, which sends all requests to the selected host, weighted or
+	// least-outstanding.
+	//
+	// Default: none
+	Balancing string
+
 	// Prefer specifies a preferred host.
 	// If the host is not discovered or has an error, it will not be used.
 	Prefer string
@@ -170,10 +218,237 @@ type CheckConfig struct {
 	//
 	// Default: 5
 	Concurrency int
+
+	// ExpectStatus lists the status codes of a healthy host.
+	//
+	// Default: any 2xx status code
+	ExpectStatus []int
+
+	// ExpectHeaders lists response headers and the value they must have for a host to be healthy.
+	ExpectHeaders map[string]string
+
+	// ExpectBody is a regular expression the response body must match for a host to be healthy.
+	ExpectBody string
+}
+
+// httpChecker builds the checker verifying the check response.
+func (c CheckConfig) httpChecker() (*selecthost.HTTPChecker, error) {
+	path := c.Path
+	if path == "" {
+		path = "/readyz"
+	}
+
+	checker := &selecthost.HTTPChecker{
+		Scheme:       c.Scheme,
+		Path:         path,
+		ExpectStatus: c.ExpectStatus,
+		ExpectHeader: c.ExpectHeaders,
+	}
+
+	if c.ExpectBody != "" {
+		re, err := regexp.Compile(c.ExpectBody)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidExpectBody, err)
+		}
+
+		checker.MatchBody = re.Match
+	}
+
+	return checker, nil
+}
+
+// CacheConfig defines the configuration for caching CheckAccess decisions.
+type CacheConfig struct {
+	// Enabled enables caching of permissions-api decisions.
+	//
+	// Default: false
+	Enabled bool
+
+	// AllowTTL defines how long an allowed decision is cached for.
+	// A zero or negative value disables caching of allowed decisions.
+	//
+	// Default: 30s
+	AllowTTL time.Duration
+
+	// DenyTTL defines how long a denied decision is cached for.
+	// A zero or negative value disables caching of denied decisions.
+	//
+	// Default: 10s
+	DenyTTL time.Duration
+
+	// MaxEntries limits the number of decisions held in memory.
+	// Once reached, the least recently used decisions are evicted.
+	//
+	// Default: 10000
+	MaxEntries int
+}
+
+func (c CacheConfig) withDefaults() CacheConfig {
+	if c.MaxEntries <= 0 {
+		c.MaxEntries = defaultCacheMaxEntries
+	}
+
+	return c
+}
+
+// CircuitBreakerConfig defines the configuration of the per-host circuit breakers.
+type CircuitBreakerConfig struct {
+	// Enabled enables failing requests immediately to hosts which keep failing.
+	//
+	// Default: false
+	Enabled bool
+
+	// FailureThreshold is the number of consecutive failed requests to a host, either errors,
+	// including attempt timeouts, or 5xx responses, which opens the host's circuit.
+	//
+	// Default: 5
+	FailureThreshold int
+
+	// OpenDuration is how long a host's circuit stays open before a probe request is allowed.
+	//
+	// Default: 30s
+	OpenDuration time.Duration
+
+	// HalfOpenProbes is the number of consecutive successful probe requests which close the circuit.
+	//
+	// Default: 1
+	HalfOpenProbes int
+}
+
+func (c CircuitBreakerConfig) withDefaults() CircuitBreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaultCircuitFailureThreshold
+	}
+
+	if c.OpenDuration <= 0 {
+		c.OpenDuration = defaultCircuitOpenDuration
+	}
+
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = defaultCircuitHalfOpenProbes
+	}
+
+	return c
+}
+
+// RetryConfig defines how failed permissions-api requests are retried.
+// The total time spent on a request, including retries, is bounded by the deadline of the incoming request.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of times a failed request is retried.
+	// A negative value disables retries.
+	//
+	// Default: 4
+	MaxRetries int
+
+	// WaitMin is the minimum time waited before retrying a request.
+	//
+	// Default: 100ms
+	WaitMin time.Duration
+
+	// WaitMax is the maximum time waited before retrying a request.
+	//
+	// Default: 2s
+	WaitMax time.Duration
+
+	// AttemptTimeout is the maximum time a single request attempt may take. When permissions-api
+	// hosts are discovered, an attempt includes failing over to other hosts, so the timeout covers
+	// every host the attempt is sent to.
+	//
+	// Default: 5s
+	AttemptTimeout time.Duration
+
+	// Budget defines the retry budget.
+	Budget RetryBudgetConfig
+}
+
+func (c RetryConfig) withDefaults() RetryConfig {
+	switch {
+	case c.MaxRetries < 0:
+		c.MaxRetries = 0
+	case c.MaxRetries == 0:
+		c.MaxRetries = defaultRetryMax
+	}
+
+	if c.WaitMin <= 0 {
+		c.WaitMin = defaultRetryWaitMin
+	}
+
+	if c.WaitMax <= 0 {
+		c.WaitMax = defaultRetryWaitMax
+	}
+
+	if c.WaitMax < c.WaitMin {
+		c.WaitMax = c.WaitMin
+	}
+
+	if c.AttemptTimeout <= 0 {
+		c.AttemptTimeout = defaultRetryAttemptTimeout
+	}
+
+	return c
+}
+
+// RetryBudgetConfig defines the retry budget, which limits retries to a ratio of requests so
+// retries can not overload permissions-api during an outage.
+type RetryBudgetConfig struct {
+	// Ratio is the maximum ratio of retries to requests made within the window.
+	// A negative value disables the retry budget.
+	//
+	// Default: 0.2
+	Ratio float64
+
+	// MinPerSecond is the number of retries per second allowed regardless of the ratio, so requests
+	// can still be retried when there is little traffic. A negative value allows none.
+	//
+	// Default: 10
+	MinPerSecond int
+
+	// Window is the sliding window over which requests and retries are counted.
+	//
+	// Default: 10s
+	Window time.Duration
+}
+
+func (c RetryBudgetConfig) withDefaults() RetryBudgetConfig {
+	if c.Ratio == 0 {
+		c.Ratio = defaultRetryBudgetRatio
+	}
+
+	switch {
+	case c.MinPerSecond < 0:
+		c.MinPerSecond = 0
+	case c.MinPerSecond == 0:
+		c.MinPerSecond = defaultRetryBudgetMinPerSecond
+	}
+
+	if c.Window < time.Second {
+		c.Window = defaultRetryBudgetWindow
+	}
+
+	return c
 }
 
 // AddFlags sets the command line flags for the permissions-api client.
 func AddFlags(flags *pflag.FlagSet) {
 	flags.Bool("permissions.disable", false, "disables permissions service")
 	flags.String("permissions.host", "", "permissions-api host to use")
+	flags.Int("permissions.peractionconcurrency", defaultPerActionConcurrency, "maximum concurrent permissions-api requests when evaluating actions individually")
+
+	flags.Bool("permissions.cache.enabled", false, "enables caching of permissions-api decisions")
+	flags.Duration("permissions.cache.allowttl", 30*time.Second, "duration to cache allowed decisions for") //nolint:mnd
+	flags.Duration("permissions.cache.denyttl", 10*time.Second, "duration to cache denied decisions for")   //nolint:mnd
+	flags.Int("permissions.cache.maxentries", defaultCacheMaxEntries, "maximum number of decisions to cache")
+
+	flags.Bool("permissions.circuitbreaker.enabled", false, "enables failing requests immediately to permissions-api hosts which keep failing")
+	flags.Int("permissions.circuitbreaker.failurethreshold", defaultCircuitFailureThreshold, "consecutive failed requests which open a host's circuit")
+	flags.Duration("permissions.circuitbreaker.openduration", defaultCircuitOpenDuration, "duration a host's circuit stays open before a probe request is allowed")
+	flags.Int("permissions.circuitbreaker.halfopenprobes", defaultCircuitHalfOpenProbes, "consecutive successful probe requests which close a host's circuit")
+
+	flags.Int("permissions.retry.maxretries", defaultRetryMax, "maximum number of times a failed permissions-api request is retried, a negative value disables retries")
+	flags.Duration("permissions.retry.waitmin", defaultRetryWaitMin, "minimum duration to wait before retrying a permissions-api request")
+	flags.Duration("permissions.retry.waitmax", defaultRetryWaitMax, "maximum duration to wait before retrying a permissions-api request")
+	flags.Duration("permissions.retry.attempttimeout", defaultRetryAttemptTimeout, "maximum duration of a single permissions-api request attempt")
+	flags.Float64("permissions.retry.budget.ratio", defaultRetryBudgetRatio, "maximum ratio of retries to permissions-api requests, a negative value disables the retry budget")
+	flags.Int("permissions.retry.budget.minpersecond", defaultRetryBudgetMinPerSecond, "retries per second allowed regardless of the retry budget ratio, a negative value allows none")
+	flags.Duration("permissions.retry.budget.window", defaultRetryBudgetWindow, "sliding window over which the retry budget is calculated")
 }
@@ -0,0 +1,29 @@
+package permissions
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConfigHTTPChecker(t *testing.T) {
+	t.Parallel()
+
+	checker, err := CheckConfig{
+		ExpectStatus:  []int{http.StatusOK},
+		ExpectHeaders: map[string]string{"x-status": "ready"},
+		ExpectBody:    `"status":\s*"ok"`,
+	}.httpChecker()
+	require.NoError(t, err)
+
+	assert.Equal(t, "/readyz", checker.Path, "expected default check path")
+	assert.Equal(t, []int{http.StatusOK}, checker.ExpectStatus)
+	assert.Equal(t, map[string]string{"x-status": "ready"}, checker.ExpectHeader)
+	assert.True(t, checker.MatchBody([]byte(`{"status": "ok"}`)), "expected body to match")
+	assert.False(t, checker.MatchBody([]byte(`{"status": "degraded"}`)), "expected body to not match")
+
+	_, err = CheckConfig{ExpectBody: "("}.httpChecker()
+	assert.ErrorIs(t, err, ErrInvalidExpectBody)
+}
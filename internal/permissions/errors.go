@@ -17,4 +17,14 @@ var (
 	// ErrUnexpectedResponse represents an error state where permissions-api returned an
 	// unexpected response.
 	ErrUnexpectedResponse = errors.New("unexpected response from server")
+
+	// ErrUnavailable is wrapped by errors returned when permissions-api could not be reached or
+	// responded with a 5xx status code, as opposed to rejecting the request.
+	ErrUnavailable = errors.New("permissions-api unavailable")
+
+	// ErrCircuitOpen is returned when a request is not made because the circuit of the host is open.
+	ErrCircuitOpen = errors.New("permissions-api circuit open")
+
+	// ErrInvalidExpectBody is returned when the expected host check body is not a valid regular expression.
+	ErrInvalidExpectBody = errors.New("invalid discovery check expected body")
 )
@@ -0,0 +1,307 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+const (
+	defaultRetryMax            = 4
+	defaultRetryWaitMin        = 100 * time.Millisecond
+	defaultRetryWaitMax        = 2 * time.Second
+	defaultRetryAttemptTimeout = 5 * time.Second
+
+	defaultRetryBudgetRatio        = 0.2
+	defaultRetryBudgetMinPerSecond = 10
+	defaultRetryBudgetWindow       = 10 * time.Second
+
+	retrySkippedDeadline = "deadline"
+	retrySkippedBudget   = "budget"
+)
+
+type retryAttemptsKey struct{}
+
+// retryAttempts counts the retries and host failovers made for a single request.
+type retryAttempts struct {
+	count     int
+	failovers int
+}
+
+// withRetryAttempts returns a context tracking the retries made for a request, allowing the
+// retry policy to tell whether another attempt will actually be made.
+func withRetryAttempts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAttemptsKey{}, &retryAttempts{})
+}
+
+// retryPolicy decides whether a failed permissions-api request is retried.
+type retryPolicy struct {
+	maxRetries int
+	waitMin    time.Duration
+	waitMax    time.Duration
+	budget     *retryBudget
+
+	// failover is true when another host may be selected for the next attempt.
+	failover bool
+}
+
+func newRetryPolicy(config RetryConfig, failover bool) *retryPolicy {
+	config = config.withDefaults()
+
+	return &retryPolicy{
+		maxRetries: config.MaxRetries,
+		waitMin:    config.WaitMin,
+		waitMax:    config.WaitMax,
+		budget:     newRetryBudget(config.Budget),
+		failover:   failover,
+	}
+}
+
+// configure applies the policy to the provided client.
+func (p *retryPolicy) configure(httpClient *retryablehttp.Client) {
+	httpClient.RetryMax = p.maxRetries
+	httpClient.RetryWaitMin = p.waitMin
+	httpClient.RetryWaitMax = p.waitMax
+	httpClient.Backoff = retryablehttp.DefaultBackoff
+	httpClient.CheckRetry = p.checkRetry
+	httpClient.RequestLogHook = func(_ retryablehttp.Logger, _ *http.Request, attempt int) {
+		if attempt > 0 {
+			metrics.IncPermissionsRetries()
+
+			return
+		}
+
+		p.budget.deposit()
+	}
+}
+
+// checkRetry implements retryablehttp.CheckRetry. On top of the default policy, a retry is skipped
+// when the request's deadline would pass before the next attempt or the retry budget is exhausted.
+func (p *retryPolicy) checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	// Without host discovery the next attempt would be made to the same open circuit.
+	if !p.failover && errors.Is(err, ErrCircuitOpen) {
+		return false, err
+	}
+
+	retry, checkErr := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	if !retry {
+		return false, checkErr
+	}
+
+	attempt, failovers := 0, 0
+
+	if attempts, ok := ctx.Value(retryAttemptsKey{}).(*retryAttempts); ok {
+		attempt = attempts.count
+		failovers = attempts.failovers
+		attempts.count++
+	}
+
+	// No retries remain, the client gives up on its own.
+	if attempt >= p.maxRetries {
+		return true, nil
+	}
+
+	// The remaining retries were used failing over to other hosts.
+	if attempt+failovers >= p.maxRetries {
+		return false, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		wait := retryablehttp.DefaultBackoff(p.waitMin, p.waitMax, attempt, resp)
+
+		if time.Until(deadline) < wait {
+			skipRetry(ctx, retrySkippedDeadline)
+
+			return false, nil
+		}
+	}
+
+	if !p.budget.withdraw() {
+		skipRetry(ctx, retrySkippedBudget)
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// allowFailover implements the [selecthost.AllowFailover] function. Failing over to another host
+// counts as a retry, so it is limited by the remaining retries of the request and the retry budget.
+func (p *retryPolicy) allowFailover(r *http.Request) bool {
+	ctx := r.Context()
+
+	attempts, ok := ctx.Value(retryAttemptsKey{}).(*retryAttempts)
+	if ok && attempts.count+attempts.failovers >= p.maxRetries {
+		return false
+	}
+
+	if !p.budget.withdraw() {
+		skipRetry(ctx, retrySkippedBudget)
+
+		return false
+	}
+
+	if ok {
+		attempts.failovers++
+	}
+
+	metrics.IncPermissionsRetries()
+
+	return true
+}
+
+func skipRetry(ctx context.Context, reason string) {
+	metrics.IncPermissionsRetriesSkipped(reason)
+
+	trace.SpanFromContext(ctx).AddEvent("retry skipped", trace.WithAttributes(
+		attribute.String("permissions.retry.skipped", reason),
+	))
+}
+
+// attemptTimeout is a round tripper bounding each request attempt, including failing over to
+// other hosts and reading the response body, by the attempt timeout. The attempt context ends with
+// [selecthost.ErrAttemptTimeout], so a host which hangs is recorded as failed, while a request
+// abandoned by its caller is not.
+type attemptTimeout struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *attemptTimeout) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeoutCause(r.Context(), t.timeout, selecthost.ErrAttemptTimeout)
+
+	resp, err := t.base.RoundTrip(r.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		if errors.Is(context.Cause(ctx), selecthost.ErrAttemptTimeout) && !errors.Is(err, selecthost.ErrAttemptTimeout) {
+			err = fmt.Errorf("%w: %w", selecthost.ErrAttemptTimeout, err)
+		}
+
+		return resp, err
+	}
+
+	// The attempt ends once the response body has been closed.
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// cancelBody cancels the attempt context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
+// retryBucket counts the requests and retries made within a single second.
+type retryBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// retryBudget limits retries to a ratio of the requests made within a sliding window, plus a
+// minimum number of retries per second so low traffic can still be retried.
+// A nil budget allows every retry.
+type retryBudget struct {
+	ratio        float64
+	minPerSecond int
+
+	mu      sync.Mutex
+	buckets []retryBucket
+
+	now func() time.Time
+}
+
+func newRetryBudget(config RetryBudgetConfig) *retryBudget {
+	config = config.withDefaults()
+
+	if config.Ratio < 0 {
+		return nil
+	}
+
+	seconds := int((config.Window + time.Second - 1) / time.Second)
+
+	return &retryBudget{
+		ratio:        config.Ratio,
+		minPerSecond: config.MinPerSecond,
+		buckets:      make([]retryBucket, seconds),
+		now:          time.Now,
+	}
+}
+
+// bucket returns the bucket of the current second, resetting it when it was last used for an
+// earlier second. The budget lock must be held.
+func (b *retryBudget) bucket(second int64) *retryBucket {
+	bucket := &b.buckets[second%int64(len(b.buckets))]
+
+	if bucket.second != second {
+		*bucket = retryBucket{second: second}
+	}
+
+	return bucket
+}
+
+// deposit records a request.
+func (b *retryBudget) deposit() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket(b.now().Unix()).requests++
+}
+
+// withdraw records a retry and returns true if the budget allows it.
+func (b *retryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	second := b.now().Unix()
+	window := int64(len(b.buckets))
+
+	var requests, retries int
+
+	for _, bucket := range b.buckets {
+		if bucket.second > second-window {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	allowed := b.ratio*float64(requests) + float64(b.minPerSecond)*float64(window)
+
+	if float64(retries+1) > allowed {
+		return false
+	}
+
+	b.bucket(second).retries++
+
+	return true
+}
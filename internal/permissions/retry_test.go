@@ -0,0 +1,304 @@
+package permissions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/selecthost"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+
+	budget := newRetryBudget(RetryBudgetConfig{
+		Ratio:        0.5,
+		MinPerSecond: 1,
+		Window:       2 * time.Second,
+	})
+	budget.now = func() time.Time { return now }
+
+	// The minimum allows 2 retries over the 2 second window without any requests.
+	assert.True(t, budget.withdraw(), "expected minimum retries to be allowed")
+	assert.True(t, budget.withdraw(), "expected minimum retries to be allowed")
+	assert.False(t, budget.withdraw(), "expected retries beyond the minimum to be rejected")
+
+	for range 4 {
+		budget.deposit()
+	}
+
+	assert.True(t, budget.withdraw(), "expected retries within the ratio to be allowed")
+	assert.True(t, budget.withdraw(), "expected retries within the ratio to be allowed")
+	assert.False(t, budget.withdraw(), "expected retries beyond the ratio to be rejected")
+
+	// Once the window has passed, the earlier requests and retries are no longer counted.
+	now = now.Add(2 * time.Second)
+
+	assert.True(t, budget.withdraw(), "expected budget to recover after the window")
+
+	assert.Nil(t, newRetryBudget(RetryBudgetConfig{Ratio: -1}), "expected negative ratio to disable the budget")
+	assert.NotNil(t, newRetryBudget(RetryBudgetConfig{}), "expected zero ratio to use the default budget")
+	assert.True(t, (*retryBudget)(nil).withdraw(), "expected disabled budget to allow retries")
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		config     RetryConfig
+		timeout    time.Duration
+		expectTrys int32
+	}{
+		{
+			name: "max retries",
+			config: RetryConfig{
+				MaxRetries: 2,
+				WaitMin:    time.Millisecond,
+				WaitMax:    time.Millisecond,
+			},
+			expectTrys: 3,
+		},
+		{
+			name: "retries disabled",
+			config: RetryConfig{
+				MaxRetries: -1,
+				WaitMin:    time.Millisecond,
+				WaitMax:    time.Millisecond,
+			},
+			expectTrys: 1,
+		},
+		{
+			name: "deadline",
+			config: RetryConfig{
+				MaxRetries: 4,
+				WaitMin:    time.Second,
+				WaitMax:    time.Second,
+			},
+			timeout:    500 * time.Millisecond,
+			expectTrys: 1,
+		},
+		{
+			name: "budget exhausted",
+			config: RetryConfig{
+				MaxRetries: 4,
+				WaitMin:    time.Millisecond,
+				WaitMax:    time.Millisecond,
+				Budget: RetryBudgetConfig{
+					Ratio:        2,
+					MinPerSecond: -1,
+				},
+			},
+			expectTrys: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			t.Cleanup(srv.Close)
+
+			httpClient := retryablehttp.NewClient()
+			httpClient.Logger = nil
+
+			newRetryPolicy(tc.config, false).configure(httpClient)
+
+			ctx := context.Background()
+
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				t.Cleanup(cancel)
+			}
+
+			req, err := retryablehttp.NewRequestWithContext(withRetryAttempts(ctx), http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := httpClient.Do(req)
+			if err == nil {
+				resp.Body.Close() //nolint:errcheck // error check not needed
+			}
+
+			assert.Equal(t, tc.expectTrys, requests.Load(), "unexpected number of attempts")
+		})
+	}
+}
+
+func TestRetryPolicyFailover(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		maxRetries      int
+		attempts        *retryAttempts
+		budgetExhausted bool
+		expectAllowed   bool
+		expectFailovers int
+	}{
+		{
+			name:            "retries remain",
+			maxRetries:      2,
+			attempts:        &retryAttempts{count: 1},
+			expectAllowed:   true,
+			expectFailovers: 1,
+		},
+		{
+			name:            "retries used",
+			maxRetries:      2,
+			attempts:        &retryAttempts{count: 1, failovers: 1},
+			expectFailovers: 1,
+		},
+		{
+			name:       "retries disabled",
+			maxRetries: -1,
+			attempts:   &retryAttempts{},
+		},
+		{
+			name:            "budget exhausted",
+			maxRetries:      2,
+			attempts:        &retryAttempts{},
+			budgetExhausted: true,
+		},
+		{
+			name:          "untracked",
+			maxRetries:    2,
+			expectAllowed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := newRetryPolicy(RetryConfig{MaxRetries: tc.maxRetries}, true)
+
+			if tc.budgetExhausted {
+				policy.budget = &retryBudget{
+					ratio:   1,
+					buckets: make([]retryBucket, 1),
+					now:     time.Now,
+				}
+			}
+
+			ctx := context.Background()
+
+			if tc.attempts != nil {
+				ctx = context.WithValue(ctx, retryAttemptsKey{}, tc.attempts)
+			}
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://permissions-api.example.com", nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectAllowed, policy.allowFailover(req), "unexpected failover result")
+
+			if tc.attempts != nil {
+				assert.Equal(t, tc.expectFailovers, tc.attempts.failovers, "unexpected failovers recorded")
+			}
+		})
+	}
+
+	t.Run("retry after failovers", func(t *testing.T) {
+		t.Parallel()
+
+		policy := newRetryPolicy(RetryConfig{MaxRetries: 2}, true)
+
+		ctx := context.WithValue(context.Background(), retryAttemptsKey{}, &retryAttempts{failovers: 2})
+
+		retry, err := policy.checkRetry(ctx, nil, ErrUnavailable)
+
+		assert.NoError(t, err, "no error expected")
+		assert.False(t, retry, "expected no retry once failovers used the remaining retries")
+	})
+}
+
+func TestRetryConfigDefaults(t *testing.T) {
+	t.Parallel()
+
+	config := RetryConfig{MaxRetries: -1, WaitMax: time.Millisecond}.withDefaults()
+
+	assert.Equal(t, 0, config.MaxRetries)
+	assert.Equal(t, defaultRetryWaitMin, config.WaitMin)
+	assert.Equal(t, defaultRetryWaitMin, config.WaitMax, "expected max wait to be raised to the min wait")
+	assert.Equal(t, defaultRetryAttemptTimeout, config.AttemptTimeout)
+
+	config = RetryConfig{}.withDefaults()
+
+	assert.Equal(t, defaultRetryMax, config.MaxRetries, "expected zero max retries to use the default")
+
+	budget := RetryBudgetConfig{}.withDefaults()
+
+	assert.InDelta(t, defaultRetryBudgetRatio, budget.Ratio, 0, "expected zero ratio to use the default")
+	assert.Equal(t, defaultRetryBudgetMinPerSecond, budget.MinPerSecond, "expected zero min per second to use the default")
+	assert.Equal(t, defaultRetryBudgetWindow, budget.Window)
+
+	budget = RetryBudgetConfig{Ratio: -1, MinPerSecond: -1}.withDefaults()
+
+	assert.Negative(t, budget.Ratio, "expected negative ratio to be kept to disable the budget")
+	assert.Equal(t, 0, budget.MinPerSecond, "expected negative min per second to allow none")
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		delay       time.Duration
+		expectError error
+	}{
+		{"responds", 0, nil},
+		{"hangs", time.Second, selecthost.ErrAttemptTimeout},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(tc.delay):
+					w.WriteHeader(http.StatusOK)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			client := &http.Client{
+				Transport: &attemptTimeout{base: http.DefaultTransport, timeout: 50 * time.Millisecond},
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := client.Do(req)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+
+			assert.NoError(t, resp.Body.Close(), "no error expected closing body")
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "unexpected status code")
+		})
+	}
+}
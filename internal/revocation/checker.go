@@ -0,0 +1,202 @@
+package revocation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
+)
+
+// Checker checks tokens against the revocation denylist.
+type Checker interface {
+	// CheckRevoked returns ErrTokenRevoked when the validated token claims match a revocation entry.
+	CheckRevoked(ctx context.Context, claims map[string]any) error
+}
+
+// Subscriber receives messages broadcast on a subject.
+type Subscriber interface {
+	Subscribe(ctx context.Context, subject string, handler eventsx.MessageHandler) error
+}
+
+type checker struct {
+	enabled bool
+
+	filePath string
+	file     *denylist
+	events   *denylist
+
+	maxTTL time.Duration
+
+	logger *zap.SugaredLogger
+	now    func() time.Time
+}
+
+// NewChecker creates a revocation checker with the given configuration.
+// Revocation entries are loaded from the configured file and received from the subscriber
+// until the context is canceled.
+func NewChecker(ctx context.Context, config Config, subscriber Subscriber, logger *zap.SugaredLogger) (Checker, error) {
+	if !config.Enabled {
+		return &checker{
+			enabled: false,
+		}, nil
+	}
+
+	if config.MaxTTL <= 0 {
+		config.MaxTTL = defaultMaxTTL
+	}
+
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultMaxEntries
+	}
+
+	out := &checker{
+		enabled: true,
+		file:    newDenylist(0),
+		events:  newDenylist(config.MaxEntries),
+		maxTTL:  config.MaxTTL,
+		logger:  logger.With("component", "revocation"),
+		now:     time.Now,
+	}
+
+	if config.File != "" {
+		out.filePath = filepath.Clean(config.File)
+
+		if err := out.loadFile(); err != nil {
+			return nil, err
+		}
+
+		if err := out.watchFile(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Subject != "" {
+		if err := subscriber.Subscribe(ctx, config.Subject, out.handleMessage); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to revocations: %w", err)
+		}
+	}
+
+	return out, nil
+}
+
+// CheckRevoked returns ErrTokenRevoked when the validated token claims match a revocation entry.
+func (c *checker) CheckRevoked(_ context.Context, claims map[string]any) error {
+	if !c.enabled {
+		return nil
+	}
+
+	tc := parseTokenClaims(claims)
+	now := c.now()
+
+	for _, list := range []*denylist{c.file, c.events} {
+		if entry, ok := list.match(tc, now); ok {
+			return fmt.Errorf("%w: %s", ErrTokenRevoked, entry.reason())
+		}
+	}
+
+	return nil
+}
+
+// handleMessage adds a revocation entry received from the subscriber.
+func (c *checker) handleMessage(_ context.Context, data []byte) {
+	var entry Entry
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		c.logger.Warnw("failed to parse revocation entry", "error", err)
+
+		return
+	}
+
+	if err := entry.Validate(); err != nil {
+		c.logger.Warnw("invalid revocation entry", "error", err)
+
+		return
+	}
+
+	now := c.now()
+
+	// Entries received over NATS are untrusted input, so bound how long they are kept.
+	if maxExpiresAt := now.Add(c.maxTTL); entry.ExpiresAt.IsZero() || entry.ExpiresAt.After(maxExpiresAt) {
+		entry.ExpiresAt = maxExpiresAt
+	}
+
+	if evicted, ok := c.events.add(entry, now); ok {
+		c.logger.Warnw("revocation denylist full, evicted entry expiring soonest", "evicted", evicted.reason())
+	}
+
+	c.logger.Infow("revocation entry received", "reason", entry.reason())
+}
+
+// loadFile replaces the file entries with the contents of the revocation file.
+func (c *checker) loadFile() error {
+	content, err := os.ReadFile(c.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read revocation file %s: %w", c.filePath, err)
+	}
+
+	entries, err := parseEntries(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse revocation file %s: %w", c.filePath, err)
+	}
+
+	c.file.replace(entries)
+
+	return nil
+}
+
+// watchFile reloads the revocation file whenever its directory changes.
+// The parent directory is watched so file replacements are detected.
+func (c *checker) watchFile(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create revocation file watcher: %w", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(c.filePath)); err != nil {
+		_ = watcher.Close() //nolint:errcheck // error check not needed
+
+		return fmt.Errorf("failed to watch revocation file: %w", err)
+	}
+
+	go func() {
+		defer watcher.Close() //nolint:errcheck // error check not needed
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+
+				if err := c.loadFile(); err != nil {
+					c.logger.Warnw("failed to reload revocation file", "error", err)
+
+					continue
+				}
+
+				c.logger.Debug("revocation file reloaded")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				c.logger.Warnw("revocation file watcher error", "error", err)
+			}
+		}
+	}()
+
+	return nil
+}
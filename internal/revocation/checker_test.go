@@ -0,0 +1,230 @@
+package revocation
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
+)
+
+type testSubscriber struct {
+	subject string
+	handler eventsx.MessageHandler
+}
+
+func (s *testSubscriber) Subscribe(_ context.Context, subject string, handler eventsx.MessageHandler) error {
+	s.subject = subject
+	s.handler = handler
+
+	return nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	// Write and rename so the file is replaced atomically.
+	tmp := path + ".tmp"
+
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600), "no error expected writing file")
+	require.NoError(t, os.Rename(tmp, path), "no error expected replacing file")
+}
+
+func TestCheckRevoked(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{JTI: "revoked-jti"},
+		{JTI: "subject-jti", Subject: "subject-a"},
+		{Subject: "subject-b"},
+		{Subject: "subject-c", IssuedBefore: now.Add(-time.Hour)},
+		{JTI: "expired-jti", ExpiresAt: now.Add(-time.Minute)},
+	}
+
+	testCases := []struct {
+		name        string
+		claims      map[string]any
+		expectError error
+	}{
+		{
+			"jti revoked",
+			map[string]any{"jti": "revoked-jti", "sub": "subject-z"},
+			ErrTokenRevoked,
+		},
+		{
+			"jti not revoked",
+			map[string]any{"jti": "other-jti", "sub": "subject-z"},
+			nil,
+		},
+		{
+			"jti revoked for subject",
+			map[string]any{"jti": "subject-jti", "sub": "subject-a"},
+			ErrTokenRevoked,
+		},
+		{
+			"jti not revoked for other subject",
+			map[string]any{"jti": "subject-jti", "sub": "subject-z"},
+			nil,
+		},
+		{
+			"subject revoked",
+			map[string]any{"sub": "subject-b", "iat": float64(now.Unix())},
+			ErrTokenRevoked,
+		},
+		{
+			"subject issued before",
+			map[string]any{"sub": "subject-c", "iat": float64(now.Add(-2 * time.Hour).Unix())},
+			ErrTokenRevoked,
+		},
+		{
+			"subject issued after",
+			map[string]any{"sub": "subject-c", "iat": float64(now.Unix())},
+			nil,
+		},
+		{
+			"subject missing iat",
+			map[string]any{"sub": "subject-c"},
+			ErrTokenRevoked,
+		},
+		{
+			"expired entry",
+			map[string]any{"jti": "expired-jti", "sub": "subject-z"},
+			nil,
+		},
+	}
+
+	file := newDenylist(0)
+	file.replace(entries)
+
+	c := &checker{
+		enabled: true,
+		file:    file,
+		events:  newDenylist(0),
+		logger:  zap.NewNop().Sugar(),
+		now:     func() time.Time { return now },
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.CheckRevoked(context.Background(), tc.claims)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			assert.NoError(t, err, "no error expected")
+		})
+	}
+}
+
+func TestCheckerDisabled(t *testing.T) {
+	t.Parallel()
+
+	c, err := NewChecker(context.Background(), Config{}, nil, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating checker")
+
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "any"}), "no error expected")
+}
+
+func TestCheckerFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "revocations.json")
+
+	writeFile(t, path, `[{"jti":"jti-1"}]`)
+
+	c, err := NewChecker(t.Context(), Config{Enabled: true, File: path}, nil, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating checker")
+
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-1"}), ErrTokenRevoked, "expected jti-1 to be revoked")
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-2"}), "expected jti-2 to not be revoked")
+
+	writeFile(t, path, `[{"jti":"jti-2"}]`)
+
+	assert.Eventually(t, func() bool {
+		return c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-2"}) != nil
+	}, 5*time.Second, 50*time.Millisecond, "expected jti-2 to be revoked after reload")
+
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-1"}), "expected jti-1 to no longer be revoked")
+}
+
+func TestCheckerFileInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "revocations.json")
+
+	writeFile(t, path, `[{"issuedBefore":"2025-01-01T00:00:00Z"}]`)
+
+	_, err := NewChecker(t.Context(), Config{Enabled: true, File: path}, nil, zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, ErrInvalidEntry, "expected invalid entry error")
+}
+
+func TestCheckerEvents(t *testing.T) {
+	t.Parallel()
+
+	subscriber := &testSubscriber{}
+
+	c, err := NewChecker(t.Context(), Config{Enabled: true, Subject: "iam.revocations"}, subscriber, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating checker")
+
+	require.Equal(t, "iam.revocations", subscriber.subject, "unexpected subject subscribed")
+
+	claims := map[string]any{"jti": "jti-1", "sub": "subject-a"}
+
+	require.NoError(t, c.CheckRevoked(context.Background(), claims), "expected token to not be revoked")
+
+	subscriber.handler(context.Background(), []byte(`not json`))
+	subscriber.handler(context.Background(), []byte(`{}`))
+
+	require.NoError(t, c.CheckRevoked(context.Background(), claims), "expected invalid entries to be ignored")
+
+	subscriber.handler(context.Background(), []byte(`{"sub":"subject-a"}`))
+
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), claims), ErrTokenRevoked, "expected token to be revoked")
+}
+
+func TestCheckerEventsBounded(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	subscriber := &testSubscriber{}
+
+	checkerIface, err := NewChecker(t.Context(), Config{Enabled: true, Subject: "iam.revocations", MaxTTL: time.Hour, MaxEntries: 2}, subscriber, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating checker")
+
+	c := checkerIface.(*checker)
+	c.now = func() time.Time { return now }
+
+	subscriber.handler(context.Background(), []byte(`{"jti":"jti-1"}`))
+	subscriber.handler(context.Background(), []byte(`{"jti":"jti-2","expiresAt":"2025-01-01T12:30:00Z"}`))
+
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-1"}), ErrTokenRevoked, "expected jti-1 to be revoked")
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-2"}), ErrTokenRevoked, "expected jti-2 to be revoked")
+
+	// jti-2 expires soonest so it is evicted to make room.
+	subscriber.handler(context.Background(), []byte(`{"jti":"jti-3","expiresAt":"2026-01-01T00:00:00Z"}`))
+
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-1"}), ErrTokenRevoked, "expected jti-1 to be revoked")
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-2"}), "expected jti-2 to be evicted")
+	assert.ErrorIs(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-3"}), ErrTokenRevoked, "expected jti-3 to be revoked")
+
+	// Entries without an expiry, or expiring later, are kept for at most the max TTL.
+	now = now.Add(time.Hour + time.Second)
+
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-1"}), "expected jti-1 to expire after the max TTL")
+	assert.NoError(t, c.CheckRevoked(context.Background(), map[string]any{"jti": "jti-3"}), "expected jti-3 to expire after the max TTL")
+}
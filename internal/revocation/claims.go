@@ -0,0 +1,41 @@
+package revocation
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// tokenClaims holds the claims revocation entries are matched against.
+type tokenClaims struct {
+	jti     string
+	subject string
+
+	issuedAt    time.Time
+	hasIssuedAt bool
+}
+
+func parseTokenClaims(claims map[string]any) tokenClaims {
+	var out tokenClaims
+
+	out.jti, _ = claims["jti"].(string)
+	out.subject, _ = claims["sub"].(string)
+
+	var iat float64
+
+	switch v := claims["iat"].(type) {
+	case float64:
+		iat, out.hasIssuedAt = v, true
+	case int64:
+		iat, out.hasIssuedAt = float64(v), true
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			iat, out.hasIssuedAt = f, true
+		}
+	}
+
+	if out.hasIssuedAt {
+		out.issuedAt = time.Unix(int64(iat), 0)
+	}
+
+	return out
+}
@@ -0,0 +1,45 @@
+package revocation
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultMaxTTL     = 24 * time.Hour
+	defaultMaxEntries = 10000
+)
+
+// Config represents the configuration for token revocation.
+type Config struct {
+	// Enabled enables checking validated tokens against the revocation denylist.
+	Enabled bool
+
+	// File is the path to a JSON file containing a list of revocation entries.
+	// The file is watched for changes.
+	File string
+
+	// Subject is the NATS subject revocation entries are received on.
+	// Every runtime instance receives every entry. Requires events to be enabled.
+	Subject string
+
+	// MaxTTL is the longest time an entry received from NATS is kept. Entries without an
+	// expiresAt, or expiring later, expire after MaxTTL.
+	// Default: 24h
+	MaxTTL time.Duration
+
+	// MaxEntries is the maximum number of entries received from NATS which are kept.
+	// Once reached, the entry expiring soonest is evicted for each new entry.
+	// Default: 10000
+	MaxEntries int
+}
+
+// AddFlags sets the command line flags for token revocation.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool("revocation.enabled", false, "enable checking tokens against the revocation denylist")
+	flags.String("revocation.file", "", "path to a JSON file of revocation entries")
+	flags.String("revocation.subject", "", "NATS subject to receive revocation entries on (requires events to be enabled)")
+	flags.Duration("revocation.maxttl", defaultMaxTTL, "maximum time an entry received from NATS is kept")
+	flags.Int("revocation.maxentries", defaultMaxEntries, "maximum number of entries received from NATS which are kept")
+}
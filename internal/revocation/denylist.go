@@ -0,0 +1,261 @@
+package revocation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Entry represents a revoked token, or a set of revoked tokens for a subject.
+// A token is revoked when every defined field of the entry matches the token.
+type Entry struct {
+	// JTI revokes the token with the matching jti claim.
+	JTI string `json:"jti,omitempty"`
+
+	// Subject revokes tokens with the matching sub claim.
+	Subject string `json:"sub,omitempty"`
+
+	// IssuedBefore limits the revocation to tokens with an iat claim before this time.
+	// Tokens without an iat claim are considered revoked.
+	IssuedBefore time.Time `json:"issuedBefore,omitzero"`
+
+	// ExpiresAt is the time after which the entry is no longer needed, usually the expiry of the
+	// revoked tokens. When zero, the entry never expires.
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// Validate ensures the entry matches a jti or sub.
+func (e Entry) Validate() error {
+	if e.JTI == "" && e.Subject == "" {
+		return ErrInvalidEntry
+	}
+
+	return nil
+}
+
+// expired returns true when the entry is no longer needed.
+func (e Entry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+// matches returns true when the token claims match the entry.
+func (e Entry) matches(tc tokenClaims) bool {
+	if e.JTI != "" && e.JTI != tc.jti {
+		return false
+	}
+
+	if e.Subject != "" && e.Subject != tc.subject {
+		return false
+	}
+
+	if !e.IssuedBefore.IsZero() && tc.hasIssuedAt && !tc.issuedAt.Before(e.IssuedBefore) {
+		return false
+	}
+
+	return true
+}
+
+// reason describes why a token matching the entry is revoked.
+func (e Entry) reason() string {
+	if e.JTI != "" {
+		return "jti " + e.JTI
+	}
+
+	if !e.IssuedBefore.IsZero() {
+		return fmt.Sprintf("sub %s issued before %s", e.Subject, e.IssuedBefore.Format(time.RFC3339))
+	}
+
+	return "sub " + e.Subject
+}
+
+// parseEntries parses a JSON list of revocation entries.
+func parseEntries(content []byte) ([]Entry, error) {
+	var entries []Entry
+
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		if err := entry.Validate(); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+	}
+
+	return entries, nil
+}
+
+// denylist indexes revocation entries by jti and sub.
+type denylist struct {
+	mu sync.RWMutex
+
+	byJTI     map[string][]Entry
+	bySubject map[string][]Entry
+
+	// maxEntries limits the number of entries added. Zero means no limit.
+	maxEntries int
+	size       int
+}
+
+func newDenylist(maxEntries int) *denylist {
+	return &denylist{
+		byJTI:      make(map[string][]Entry),
+		bySubject:  make(map[string][]Entry),
+		maxEntries: maxEntries,
+	}
+}
+
+// index returns the index the entry is stored in along with its key.
+func (d *denylist) index(entry Entry) (map[string][]Entry, string) {
+	if entry.JTI != "" {
+		return d.byJTI, entry.JTI
+	}
+
+	return d.bySubject, entry.Subject
+}
+
+// add adds the entry to the denylist, pruning expired entries.
+// When the denylist is full, the entry expiring soonest is evicted and returned.
+func (d *denylist) add(entry Entry, now time.Time) (Entry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.prune(now)
+
+	var (
+		evicted Entry
+		ok      bool
+	)
+
+	if d.maxEntries > 0 && d.size >= d.maxEntries {
+		evicted, ok = d.evict()
+	}
+
+	index, key := d.index(entry)
+
+	index[key] = append(index[key], entry)
+	d.size++
+
+	return evicted, ok
+}
+
+// evict removes the entry expiring soonest. Entries which never expire are evicted last.
+// The caller must hold the write lock.
+func (d *denylist) evict() (Entry, bool) {
+	var (
+		soonest Entry
+		found   bool
+	)
+
+	for _, index := range []map[string][]Entry{d.byJTI, d.bySubject} {
+		for _, entries := range index {
+			for _, entry := range entries {
+				if !found || expiresBefore(entry, soonest) {
+					soonest = entry
+					found = true
+				}
+			}
+		}
+	}
+
+	if !found {
+		return Entry{}, false
+	}
+
+	index, key := d.index(soonest)
+
+	entries := index[key]
+
+	for i, entry := range entries {
+		if entry == soonest {
+			entries = append(entries[:i], entries[i+1:]...)
+
+			break
+		}
+	}
+
+	if len(entries) == 0 {
+		delete(index, key)
+	} else {
+		index[key] = entries
+	}
+
+	d.size--
+
+	return soonest, true
+}
+
+// expiresBefore returns true when entry a expires before entry b.
+func expiresBefore(a, b Entry) bool {
+	switch {
+	case a.ExpiresAt.IsZero():
+		return false
+	case b.ExpiresAt.IsZero():
+		return true
+	default:
+		return a.ExpiresAt.Before(b.ExpiresAt)
+	}
+}
+
+// replace replaces all entries in the denylist.
+func (d *denylist) replace(entries []Entry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	clear(d.byJTI)
+	clear(d.bySubject)
+
+	for _, entry := range entries {
+		index, key := d.index(entry)
+
+		index[key] = append(index[key], entry)
+	}
+
+	d.size = len(entries)
+}
+
+// prune removes expired entries. The caller must hold the write lock.
+func (d *denylist) prune(now time.Time) {
+	for _, index := range []map[string][]Entry{d.byJTI, d.bySubject} {
+		for key, entries := range index {
+			kept := entries[:0]
+
+			for _, entry := range entries {
+				if !entry.expired(now) {
+					kept = append(kept, entry)
+				}
+			}
+
+			d.size -= len(entries) - len(kept)
+
+			if len(kept) == 0 {
+				delete(index, key)
+			} else {
+				index[key] = kept
+			}
+		}
+	}
+}
+
+// match returns the first unexpired entry matching the token claims.
+func (d *denylist) match(tc tokenClaims, now time.Time) (Entry, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	candidates := [][]Entry{d.bySubject[tc.subject]}
+
+	if tc.jti != "" {
+		candidates = append(candidates, d.byJTI[tc.jti])
+	}
+
+	for _, entries := range candidates {
+		for _, entry := range entries {
+			if !entry.expired(now) && entry.matches(tc) {
+				return entry, true
+			}
+		}
+	}
+
+	return Entry{}, false
+}
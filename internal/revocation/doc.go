@@ -0,0 +1,2 @@
+// Package revocation provides a denylist of revoked tokens.
+package revocation
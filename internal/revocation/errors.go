@@ -0,0 +1,11 @@
+package revocation
+
+import "errors"
+
+var (
+	// ErrTokenRevoked is returned when a token matches a revocation entry.
+	ErrTokenRevoked = errors.New("token revoked")
+
+	// ErrInvalidEntry is returned when a revocation entry has neither a jti nor a sub.
+	ErrInvalidEntry = errors.New("revocation entry must define a jti or sub")
+)
@@ -0,0 +1,125 @@
+package selecthost
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+)
+
+// Balancing defines how requests are distributed across discovered hosts.
+type Balancing string
+
+const (
+	// BalanceNone sends all requests to the selected host.
+	BalanceNone Balancing = "none"
+	// BalanceWeighted spreads requests across the healthy hosts of the lowest priority tier using
+	// the weighted random selection defined by RFC 2782.
+	BalanceWeighted Balancing = "weighted"
+	// BalanceLeastOutstanding sends each request to the healthy host of the lowest priority tier
+	// with the fewest outstanding requests.
+	BalanceLeastOutstanding Balancing = "least-outstanding"
+)
+
+var (
+	// ErrInvalidBalancing is returned when an unknown balancing mode is configured.
+	ErrInvalidBalancing = fmt.Errorf("%w: invalid balancing mode", ErrSelectHost)
+)
+
+// balancedHost returns the host a request should be sent to according to the balancing mode.
+// When balancing is disabled, or no discovered host is healthy, the selected host is returned.
+func (s *Selector) balancedHost(ctx context.Context) (Host, error) {
+	selected, err := s.GetHost(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.balancing != BalanceWeighted && s.balancing != BalanceLeastOutstanding {
+		return selected, nil
+	}
+
+	candidates := s.priorityTier()
+
+	switch {
+	case len(candidates) == 0:
+		return selected, nil
+	case s.balancing == BalanceLeastOutstanding:
+		return leastOutstanding(candidates), nil
+	default:
+		return weightedRandom(candidates), nil
+	}
+}
+
+// priorityTier returns the healthy discovered hosts sharing the lowest priority.
+func (s *Selector) priorityTier() Hosts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tier Hosts
+
+	for _, host := range s.hosts {
+		if host.Err() != nil {
+			continue
+		}
+
+		switch {
+		case len(tier) == 0 || host.Record().Priority < tier[0].Record().Priority:
+			tier = Hosts{host}
+		case host.Record().Priority == tier[0].Record().Priority:
+			tier = append(tier, host)
+		}
+	}
+
+	return tier
+}
+
+// weightedRandom selects a host with a probability proportional to its weight, as defined by
+// RFC 2782. Hosts with a zero weight are only selected when every host has a zero weight, in
+// which case a host is selected uniformly.
+func weightedRandom(hosts Hosts) Host {
+	var total int
+
+	for _, host := range hosts {
+		total += int(host.Record().Weight)
+	}
+
+	if total == 0 {
+		return hosts[rand.IntN(len(hosts))] //nolint:gosec // load balancing does not need a secure random source
+	}
+
+	pick := rand.IntN(total) //nolint:gosec // load balancing does not need a secure random source
+
+	var sum int
+
+	for _, host := range hosts {
+		sum += int(host.Record().Weight)
+
+		if sum > pick {
+			return host
+		}
+	}
+
+	return hosts[len(hosts)-1]
+}
+
+// leastOutstanding selects the host with the fewest outstanding requests, choosing randomly
+// between hosts with the same number.
+func leastOutstanding(hosts Hosts) Host {
+	var (
+		least  []Host
+		fewest int64
+	)
+
+	for _, host := range hosts {
+		outstanding := host.Outstanding()
+
+		switch {
+		case len(least) == 0 || outstanding < fewest:
+			least = []Host{host}
+			fewest = outstanding
+		case outstanding == fewest:
+			least = append(least, host)
+		}
+	}
+
+	return least[rand.IntN(len(least))] //nolint:gosec // load balancing does not need a secure random source
+}
@@ -0,0 +1,189 @@
+package selecthost
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testBalancedSelector(t *testing.T, balancing Balancing, records ...net.SRV) *Selector {
+	t.Helper()
+
+	selector := &Selector{
+		logger:    zap.NewNop().Sugar(),
+		target:    "host.example.com",
+		balancing: balancing,
+		runCh:     make(chan struct{}),
+		startWait: make(chan struct{}),
+	}
+
+	selector.startOnce.Do(func() {})
+
+	t.Cleanup(func() { close(selector.runCh) })
+
+	for _, record := range records {
+		selector.hosts = append(selector.hosts, newHost(selector, record.Target, "", record))
+	}
+
+	selector.selected = selector.hosts[0]
+
+	return selector
+}
+
+func TestBalancedHost(t *testing.T) {
+	t.Parallel()
+
+	const samples = 10000
+
+	testCases := []struct {
+		name      string
+		balancing Balancing
+		records   []net.SRV
+		failed    []string
+		expect    map[string]float64
+	}{
+		{
+			"disabled",
+			BalanceNone,
+			[]net.SRV{{Target: "host1", Weight: 1}, {Target: "host2", Weight: 1}},
+			nil,
+			map[string]float64{"host1": 1},
+		},
+		{
+			"weighted",
+			BalanceWeighted,
+			[]net.SRV{{Target: "host1", Weight: 10}, {Target: "host2", Weight: 30}},
+			nil,
+			map[string]float64{"host1": 0.25, "host2": 0.75},
+		},
+		{
+			"weighted zero weights",
+			BalanceWeighted,
+			[]net.SRV{{Target: "host1"}, {Target: "host2"}},
+			nil,
+			map[string]float64{"host1": 0.5, "host2": 0.5},
+		},
+		{
+			"lowest priority tier",
+			BalanceWeighted,
+			[]net.SRV{{Target: "host1", Priority: 1, Weight: 1}, {Target: "host2", Priority: 1, Weight: 1}, {Target: "host3", Priority: 2, Weight: 100}},
+			nil,
+			map[string]float64{"host1": 0.5, "host2": 0.5},
+		},
+		{
+			"failed hosts skipped",
+			BalanceWeighted,
+			[]net.SRV{{Target: "host1", Priority: 1, Weight: 1}, {Target: "host2", Priority: 1, Weight: 1}, {Target: "host3", Priority: 2, Weight: 1}},
+			[]string{"host1"},
+			map[string]float64{"host2": 1},
+		},
+		{
+			"next tier when tier failed",
+			BalanceLeastOutstanding,
+			[]net.SRV{{Target: "host1", Priority: 1}, {Target: "host2", Priority: 2}, {Target: "host3", Priority: 2}},
+			[]string{"host1"},
+			map[string]float64{"host2": 0.5, "host3": 0.5},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			selector := testBalancedSelector(t, tc.balancing, tc.records...)
+
+			for _, host := range selector.hosts {
+				for _, failed := range tc.failed {
+					if host.Host() == failed {
+						host.setError(errTestBase)
+					}
+				}
+			}
+
+			counts := make(map[string]int)
+
+			for range samples {
+				host, err := selector.balancedHost(context.Background())
+				require.NoError(t, err)
+
+				counts[host.Host()]++
+			}
+
+			assert.Len(t, counts, len(tc.expect), "unexpected hosts selected: %v", counts)
+
+			for name, ratio := range tc.expect {
+				assert.InDelta(t, ratio, float64(counts[name])/samples, 0.05, "unexpected share of requests for %s", name)
+			}
+		})
+	}
+}
+
+func TestLeastOutstanding(t *testing.T) {
+	t.Parallel()
+
+	selector := testBalancedSelector(t, BalanceLeastOutstanding,
+		net.SRV{Target: "host1"},
+		net.SRV{Target: "host2"},
+		net.SRV{Target: "host3"},
+	)
+
+	release1 := selector.hosts[0].acquire()
+	release3 := selector.hosts[2].acquire()
+
+	host, err := selector.balancedHost(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "host2", host.Host(), "expected host without outstanding requests")
+
+	release1()
+	release1()
+
+	assert.Equal(t, int64(0), selector.hosts[0].Outstanding(), "expected release to only be counted once")
+
+	release3()
+
+	// Outstanding requests are released once the response body is closed.
+	transport := &Transport{
+		Selector: selector,
+		Base:     &testTransport{},
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://host.example.com/test-path", nil)
+	require.NoError(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+
+	var outstanding int64
+
+	for _, host := range selector.hosts {
+		outstanding += host.Outstanding()
+	}
+
+	assert.Equal(t, int64(1), outstanding, "expected request to be outstanding")
+
+	require.NoError(t, resp.Body.Close())
+
+	for _, host := range selector.hosts {
+		assert.Equal(t, int64(0), host.Outstanding(), "expected request to be released")
+	}
+}
+
+func TestBalanceOption(t *testing.T) {
+	t.Parallel()
+
+	selector := &Selector{}
+
+	require.NoError(t, Balance("")(selector))
+	assert.Equal(t, BalanceNone, selector.balancing)
+
+	require.NoError(t, Balance(BalanceWeighted)(selector))
+	assert.Equal(t, BalanceWeighted, selector.balancing)
+
+	assert.ErrorIs(t, Balance("round-robin")(selector), ErrInvalidBalancing)
+}
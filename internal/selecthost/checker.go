@@ -0,0 +1,279 @@
+package selecthost
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var (
+	// ErrUnexpectedHeader is returned when a check response is missing an expected header value.
+	ErrUnexpectedHeader = fmt.Errorf("%w: unexpected header", ErrSelectHost)
+	// ErrUnexpectedBody is returned when a check response body does not match.
+	ErrUnexpectedBody = fmt.Errorf("%w: unexpected body", ErrSelectHost)
+	// ErrNotServing is returned when a gRPC health check reports the service is not serving.
+	ErrNotServing = fmt.Errorf("%w: service not serving", ErrSelectHost)
+	// ErrMissingPort is returned when a host without a port is checked by a checker requiring one.
+	ErrMissingPort = fmt.Errorf("%w: host has no port", ErrSelectHost)
+)
+
+// Checker checks the health of a host.
+type Checker interface {
+	// Check runs a single check against the host, returning an error if the host is unhealthy.
+	// The context is canceled once the selector's check timeout is reached.
+	Check(ctx context.Context, host Host) error
+}
+
+// CheckerFunc is an adapter allowing a function to be used as a [Checker].
+type CheckerFunc func(ctx context.Context, host Host) error
+
+// Check implements [Checker].
+func (f CheckerFunc) Check(ctx context.Context, host Host) error {
+	return f(ctx, host)
+}
+
+var (
+	_ Checker = (*HTTPChecker)(nil)
+	_ Checker = (*GRPCChecker)(nil)
+	_ Checker = (*TCPChecker)(nil)
+	_ Checker = (*TLSChecker)(nil)
+)
+
+// HTTPChecker checks a host by sending a GET request.
+// This is the default checker, using the selector's check scheme and path.
+type HTTPChecker struct {
+	// Scheme sets the request scheme.
+	// Default is http unless the host port is 443, https is used then.
+	Scheme string
+
+	// Path sets the request path.
+	Path string
+
+	// Header is sent with every check request.
+	Header http.Header
+
+	// ExpectStatus lists the status codes of a healthy host.
+	// Default: any 2xx status code
+	ExpectStatus []int
+
+	// ExpectHeader lists response headers and the value they must have.
+	ExpectHeader map[string]string
+
+	// MatchBody, if set, must return true for the response body of a healthy host.
+	// For example, [regexp.Regexp.Match].
+	MatchBody func(body []byte) bool
+
+	// Client sets the client requests are made with.
+	// Default: a client with a 10 second timeout
+	Client *http.Client
+}
+
+// Check implements [Checker].
+func (c *HTTPChecker) Check(ctx context.Context, host Host) error {
+	uri := checkURI(c.Scheme, c.Path, host.Host(), host.Port())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	client := c.Client
+	if client == nil {
+		client = httpClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	// Consume body so connection can be reused.
+	// If an error occurs reading the body, ignore.
+	body, _ := io.ReadAll(resp.Body)
+
+	if !c.expectedStatus(resp.StatusCode) {
+		return fmt.Errorf("%w: %d: %s", ErrUnexpectedStatusCode, resp.StatusCode, string(body))
+	}
+
+	for key, value := range c.ExpectHeader {
+		if got := resp.Header.Get(key); got != value {
+			return fmt.Errorf("%w: %s: %q", ErrUnexpectedHeader, key, got)
+		}
+	}
+
+	if c.MatchBody != nil && !c.MatchBody(body) {
+		return fmt.Errorf("%w: %s", ErrUnexpectedBody, string(body))
+	}
+
+	return nil
+}
+
+func (c *HTTPChecker) expectedStatus(code int) bool {
+	if len(c.ExpectStatus) == 0 {
+		return code >= 200 && code < 300
+	}
+
+	return slices.Contains(c.ExpectStatus, code)
+}
+
+// GRPCChecker checks a host using the gRPC health checking protocol.
+type GRPCChecker struct {
+	// Service is the name of the service checked.
+	// Default: "" which checks the overall health of the server
+	Service string
+
+	// TLSConfig enables TLS using the provided config.
+	// Default: no TLS
+	TLSConfig *tls.Config
+
+	// DialOptions are added to the options used to connect to the host.
+	DialOptions []grpc.DialOption
+}
+
+// Check implements [Checker].
+func (c *GRPCChecker) Check(ctx context.Context, host Host) error {
+	addr, err := checkAddress(host)
+	if err != nil {
+		return err
+	}
+
+	creds := insecure.NewCredentials()
+
+	if c.TLSConfig != nil {
+		creds = credentials.NewTLS(c.TLSConfig)
+	}
+
+	conn, err := grpc.NewClient(addr, append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, c.DialOptions...)...)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close() //nolint:errcheck
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: c.Service})
+	if err != nil {
+		return err
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("%w: %s", ErrNotServing, resp.GetStatus())
+	}
+
+	return nil
+}
+
+// TCPChecker checks a host by opening a TCP connection.
+type TCPChecker struct {
+	// Dialer sets the dialer used to connect.
+	Dialer *net.Dialer
+}
+
+// Check implements [Checker].
+func (c *TCPChecker) Check(ctx context.Context, host Host) error {
+	addr, err := checkAddress(host)
+	if err != nil {
+		return err
+	}
+
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// TLSChecker checks a host by completing a TLS handshake.
+type TLSChecker struct {
+	// Config sets the TLS configuration.
+	// If no ServerName is set, the host name is used.
+	Config *tls.Config
+
+	// Dialer sets the dialer used to connect.
+	Dialer *net.Dialer
+}
+
+// Check implements [Checker].
+func (c *TLSChecker) Check(ctx context.Context, host Host) error {
+	addr, err := checkAddress(host)
+	if err != nil {
+		return err
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if c.Config != nil {
+		config = c.Config.Clone()
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = host.Host()
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: c.Dialer,
+		Config:    config,
+	}
+
+	// DialContext completes the handshake before returning.
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
+// checkAddress returns the address of the host to connect to.
+func checkAddress(host Host) (string, error) {
+	if host.Port() == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingPort, host.ID())
+	}
+
+	return net.JoinHostPort(host.Host(), host.Port()), nil
+}
+
+// checkURI builds the URI of an HTTP check request.
+func checkURI(scheme, path, host, port string) *url.URL {
+	if scheme == "" {
+		scheme = "http"
+
+		if port == "443" {
+			scheme = "https"
+		}
+	}
+
+	// Strip port for default scheme ports.
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	return &url.URL{
+		Scheme: scheme,
+		Host:   JoinHostPort(host, port),
+		Path:   path,
+	}
+}
@@ -0,0 +1,211 @@
+package selecthost
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func testListenerHost(t *testing.T, addr string) Host {
+	t.Helper()
+
+	h, p, err := net.SplitHostPort(addr)
+	require.NoError(t, err, "no error expected splitting test server address")
+
+	return newHost(&Selector{}, h, p, net.SRV{})
+}
+
+func TestHTTPChecker(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		checker     *HTTPChecker
+		status      int
+		expectError error
+	}{
+		{
+			"success",
+			&HTTPChecker{},
+			http.StatusOK,
+			nil,
+		},
+		{
+			"unexpected status",
+			&HTTPChecker{},
+			http.StatusMultipleChoices,
+			ErrUnexpectedStatusCode,
+		},
+		{
+			"expected status",
+			&HTTPChecker{ExpectStatus: []int{http.StatusTooManyRequests}},
+			http.StatusTooManyRequests,
+			nil,
+		},
+		{
+			"status not listed",
+			&HTTPChecker{ExpectStatus: []int{http.StatusTooManyRequests}},
+			http.StatusOK,
+			ErrUnexpectedStatusCode,
+		},
+		{
+			"expected header",
+			&HTTPChecker{ExpectHeader: map[string]string{"X-Status": "ready"}},
+			http.StatusOK,
+			nil,
+		},
+		{
+			"unexpected header",
+			&HTTPChecker{ExpectHeader: map[string]string{"X-Status": "draining"}},
+			http.StatusOK,
+			ErrUnexpectedHeader,
+		},
+		{
+			"body matched",
+			&HTTPChecker{MatchBody: regexp.MustCompile(`"status":\s*"ok"`).Match},
+			http.StatusOK,
+			nil,
+		},
+		{
+			"body not matched",
+			&HTTPChecker{MatchBody: regexp.MustCompile(`"status":\s*"degraded"`).Match},
+			http.StatusOK,
+			ErrUnexpectedBody,
+		},
+		{
+			"request header",
+			&HTTPChecker{Header: http.Header{"Authorization": []string{"Bearer token"}}},
+			http.StatusAccepted,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/readyz", r.URL.Path, "unexpected check path")
+
+				for key := range tc.checker.Header {
+					assert.Equal(t, tc.checker.Header.Get(key), r.Header.Get(key), "expected header to be sent")
+				}
+
+				w.Header().Set("X-Status", "ready")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(`{"status": "ok"}`)) //nolint:errcheck // error check not needed
+			}))
+			t.Cleanup(srv.Close)
+
+			tc.checker.Path = "/readyz"
+
+			err := tc.checker.Check(context.Background(), testListenerHost(t, srv.Listener.Addr().String()))
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			assert.NoError(t, err, "no error expected")
+		})
+	}
+}
+
+func TestGRPCChecker(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("ready", healthpb.HealthCheckResponse_SERVING)
+	healthSrv.SetServingStatus("draining", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, healthSrv)
+
+	go srv.Serve(listener) //nolint:errcheck // error check not needed
+
+	t.Cleanup(srv.Stop)
+
+	host := testListenerHost(t, listener.Addr().String())
+
+	assert.NoError(t, (&GRPCChecker{}).Check(context.Background(), host), "expected server to be serving")
+	assert.NoError(t, (&GRPCChecker{Service: "ready"}).Check(context.Background(), host), "expected service to be serving")
+	assert.ErrorIs(t, (&GRPCChecker{Service: "draining"}).Check(context.Background(), host), ErrNotServing, "expected service to not be serving")
+	assert.Error(t, (&GRPCChecker{Service: "unknown"}).Check(context.Background(), host), "expected unknown service to fail")
+}
+
+func TestTCPChecker(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	host := testListenerHost(t, listener.Addr().String())
+
+	assert.NoError(t, (&TCPChecker{}).Check(context.Background(), host), "expected connection to succeed")
+
+	require.NoError(t, listener.Close())
+
+	assert.Error(t, (&TCPChecker{}).Check(context.Background(), host), "expected connection to closed listener to fail")
+
+	assert.ErrorIs(t, (&TCPChecker{}).Check(context.Background(), newHost(&Selector{}, "127.0.0.1", "", net.SRV{})), ErrMissingPort)
+}
+
+func TestTLSChecker(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	host := testListenerHost(t, srv.Listener.Addr().String())
+
+	trusted := &TLSChecker{
+		Config: &tls.Config{
+			RootCAs:    srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+			ServerName: "example.com",
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	assert.NoError(t, trusted.Check(context.Background(), host), "expected handshake to succeed")
+	assert.Error(t, (&TLSChecker{}).Check(context.Background(), host), "expected untrusted certificate to fail the handshake")
+}
+
+func TestHostRunChecker(t *testing.T) {
+	t.Parallel()
+
+	var checked Host
+
+	selector := &Selector{}
+
+	require.NoError(t, CheckWith(CheckerFunc(func(_ context.Context, host Host) error {
+		checked = host
+
+		return errTestBase
+	}))(selector))
+
+	target := newHost(selector, "host1.example.com", "8080", net.SRV{})
+
+	_, err := target.(*host).run(context.Background(), zap.NewNop().Sugar())
+
+	assert.ErrorIs(t, err, errTestBase, "expected checker error to be returned")
+	assert.Equal(t, target, checked, "expected host to be checked")
+}
@@ -1,5 +1,8 @@
 // Package selecthost handles host discovery via DNS SRV records, keeps track of healthy
 // and selects the most optimal host for use.
 //
+// Hosts are checked with an HTTP request by default. A [Checker] for the gRPC health checking
+// protocol, TCP connections, TLS handshakes or any other protocol may be set with [CheckWith].
+//
 // An HTTP [Transport] is provided which simplifies using this package with any http client.
 package selecthost
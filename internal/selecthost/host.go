@@ -4,13 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
@@ -46,6 +46,9 @@ type Host interface {
 	AverageDuration() time.Duration
 	Err() error
 	setError(err error)
+
+	Outstanding() int64
+	acquire() func()
 }
 
 // Hosts is a collection of [Host]s.
@@ -87,6 +90,8 @@ type host struct {
 
 	lastCheck Results
 	err       error
+
+	outstanding atomic.Int64
 }
 
 // ID returns the host ID.
@@ -137,6 +142,25 @@ func (h *host) setError(err error) {
 	h.err = err
 }
 
+// Outstanding returns the number of requests currently being sent to the host.
+func (h *host) Outstanding() int64 {
+	return h.outstanding.Load()
+}
+
+// acquire records a request being sent to the host and returns a func to call once the
+// request has completed.
+func (h *host) acquire() func() {
+	h.outstanding.Add(1)
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			h.outstanding.Add(-1)
+		})
+	}
+}
+
 // Before compares if the left host is before the provided host.
 func (h *host) Before(h2 Host) bool {
 	switch {
@@ -253,72 +277,42 @@ checkLoop:
 }
 
 func (h *host) buildURI() *url.URL {
-	scheme := "http"
-
-	if h.port == "443" {
-		scheme = "https"
-	}
-
-	if h.selector.checkScheme != "" {
-		scheme = h.selector.checkScheme
-	}
-
-	port := h.port
+	return checkURI(h.selector.checkScheme, h.selector.checkPath, h.host, h.port)
+}
 
-	// Strip port for default scheme ports.
-	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
-		port = ""
+// checker returns the selector's checker, defaulting to an HTTP check of the selector's check path.
+func (h *host) checker() Checker {
+	if h.selector.checker != nil {
+		return h.selector.checker
 	}
 
-	return &url.URL{
-		Scheme: scheme,
-		Host:   JoinHostPort(h.host, port),
+	return &HTTPChecker{
+		Scheme: h.selector.checkScheme,
 		Path:   h.selector.checkPath,
 	}
 }
 
 func (h *host) run(ctx context.Context, logger *zap.SugaredLogger) (time.Duration, error) {
-	uri := h.buildURI()
-
-	logger = logger.With(
-		"check.uri", uri.String(),
-	)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
-	if err != nil {
-		logger.Errorw("Failed to create check request", "error", err)
-
-		return 0, err
+	if h.selector.checker == nil {
+		logger = logger.With(
+			"check.uri", h.buildURI().String(),
+		)
 	}
 
 	start := time.Now()
 
-	resp, err := httpClient.Do(req)
-	if err != nil {
-		duration := time.Since(start)
-
-		logger.Errorw("Failed to execute check request", "error", err, "check.duration_ms", toMilliseconds(duration))
-
-		return duration, err
-	}
-
-	defer resp.Body.Close() //nolint:errcheck
-
-	// Consume body so connection can be reused.
-	// If an error occurs reading the body, ignore.
-	body, _ := io.ReadAll(resp.Body)
+	err := h.checker().Check(ctx, h)
 
 	duration := time.Since(start)
 
 	logger = logger.With(
 		"check.duration_ms", toMilliseconds(duration),
-		"check.response.status_code", resp.StatusCode,
 	)
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		logger.Errorw("Check completed with an unexpected status code", "error", err)
+	if err != nil {
+		logger.Errorw("Check completed with an error", "error", err)
 
-		return duration, fmt.Errorf("%w: %d: %s", ErrUnexpectedStatusCode, resp.StatusCode, string(body))
+		return duration, err
 	}
 
 	logger.Debug("Check completed successfully")
@@ -1,9 +1,25 @@
 package selecthost
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrServerError is recorded on a host which responded with a 5xx status code.
+	ErrServerError = fmt.Errorf("%w: server error response", ErrSelectHost)
+	// ErrAttemptTimeout is the cause a requestor sets on the context of a request attempt which
+	// timed out, using [context.WithTimeoutCause]. A request ended by it is recorded as a failure of
+	// the host, while other context errors mean the requestor went away.
+	ErrAttemptTimeout = fmt.Errorf("%w: request attempt timed out: %w", ErrSelectHost, context.DeadlineExceeded)
 )
 
 var _ http.RoundTripper = (*Transport)(nil)
@@ -15,12 +31,18 @@ type Transport struct {
 }
 
 // RoundTrip implements http.RoundTripper.
-// If the request host matches the selector service's target, the host is replaced with the selected host address.
+// If the request host matches the selector service's target, the host is replaced with the selected host address,
+// or when balancing is enabled, with a healthy host chosen by the selector's balancing mode.
 // If the request does not match, the base transport is called for the request instead.
 //
-// When the selected host is used, if the result from the base transport returns an error,
-// the selected host is marked as having that error and a new host is immediately selected.
-// The request however is not retried, instead the requestor must retry when appropriate.
+// When the host is used, if the result from the base transport is an error or a 5xx response,
+// the host is marked as having that error and a new host is immediately selected. This includes
+// requests ended by [ErrAttemptTimeout], but not requests canceled by the requestor.
+// The request is then retried on the next host without an error, up to the selector's failover
+// attempts and while the AllowFailover function permits it. A timed out request is not retried, as
+// its context has ended. Once no attempts or hosts remain, the last result is returned and the
+// requestor must retry when appropriate. Request bodies are buffered so they can be replayed to
+// the next host.
 //
 // Hosts marked with an error will get cleared upon the next successful host check cycle.
 func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -29,7 +51,7 @@ func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 		basert = http.DefaultTransport
 	}
 
-	host, err := t.Selector.GetHost(r.Context())
+	host, err := t.Selector.balancedHost(r.Context())
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +60,76 @@ func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 		return basert.RoundTrip(r)
 	}
 
+	if t.Selector.failover > 0 {
+		if r, err = bufferBody(r); err != nil {
+			return nil, err
+		}
+	}
+
+	var tried []Host
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.roundTrip(basert, r, host, attempt > 0)
+
+		hostErr := err
+		if hostErr == nil && resp.StatusCode >= http.StatusInternalServerError {
+			hostErr = fmt.Errorf("%w: status code %d", ErrServerError, resp.StatusCode)
+		}
+
+		// Failures caused by the requestor going away say nothing about the host.
+		if hostErr == nil || RequestorDone(r.Context()) {
+			return resp, err
+		}
+
+		host.setError(hostErr)
+		t.Selector.selectHost(r.Context())
+
+		tried = append(tried, host)
+
+		// A timed out attempt leaves no time to fail over, the next attempt uses the newly selected host.
+		if attempt >= t.Selector.failover || r.Context().Err() != nil {
+			return resp, err
+		}
+
+		next := t.Selector.nextHost(tried)
+		if next == nil {
+			return resp, err
+		}
+
+		if t.Selector.allowFailover != nil && !t.Selector.allowFailover(r) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck // error check not needed
+			resp.Body.Close()              //nolint:errcheck // error check not needed
+		}
+
+		trace.SpanFromContext(r.Context()).AddEvent("failover", trace.WithAttributes(
+			attribute.String("host.failed.id", host.ID()),
+			attribute.String("host.failed.error", hostErr.Error()),
+			attribute.String("host.next.id", next.ID()),
+		))
+
+		t.Selector.logger.Warnw("Request to host failed, failing over to next host",
+			"failed.host", host.ID(),
+			"failed.error", hostErr,
+			"next.host", next.ID(),
+		)
+
+		host = next
+	}
+}
+
+// RequestorDone reports whether the context was ended by the requestor rather than by an
+// [ErrAttemptTimeout] attempt timeout.
+func RequestorDone(ctx context.Context) bool {
+	return ctx.Err() != nil && !errors.Is(context.Cause(ctx), ErrAttemptTimeout)
+}
+
+// roundTrip sends the request to the provided host. When replay is true, the request body is
+// reset before the request is sent.
+func (t *Transport) roundTrip(basert http.RoundTripper, r *http.Request, host Host, replay bool) (*http.Response, error) {
 	port := host.Port()
 
 	// If no port was defined on the selected host, use the same port as the request, if one exists.
@@ -57,17 +149,69 @@ func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
 	r.URL.Host = addr
 	r.Host = addr
 
+	if replay && r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		r.Body = body
+	}
+
+	release := host.acquire()
+
 	resp, err := basert.RoundTrip(r)
 	if err != nil {
-		host.setError(err)
-		t.Selector.selectHost(r.Context())
+		release()
 
 		return resp, fmt.Errorf("selected host: '%s': %w", addr, err)
 	}
 
+	// The request remains outstanding until its response body has been closed.
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+
 	return resp, nil
 }
 
+// releaseBody calls release once the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+
+	release func()
+}
+
+// Close implements io.Closer.
+func (b *releaseBody) Close() error {
+	defer b.release()
+
+	return b.ReadCloser.Close()
+}
+
+// bufferBody reads the request body into memory so it may be sent again to another host.
+// Requests without a body or which can already replay their body are returned unchanged.
+func bufferBody(r *http.Request) (*http.Request, error) {
+	if r.Body == nil || r.Body == http.NoBody || r.GetBody != nil {
+		return r, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+
+	r.Body.Close() //nolint:errcheck // error check not needed
+
+	if err != nil {
+		return nil, err
+	}
+
+	r = r.Clone(r.Context())
+
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.Body, _ = r.GetBody() //nolint:errcheck // error check not needed
+
+	return r, nil
+}
+
 // NewTransport initialized a new Transport with the provided selector and base transport.
 // If base is nil, the default http transport is used.
 func NewTransport(selector *Selector, base http.RoundTripper) http.RoundTripper {
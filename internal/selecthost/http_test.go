@@ -5,8 +5,11 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -122,3 +125,260 @@ func TestTransportRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+type failoverTransport struct {
+	status map[string]int
+	bodies []string
+}
+
+func (t *failoverTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	t.bodies = append(t.bodies, r.URL.Host+":"+string(body))
+
+	status, ok := t.status[r.URL.Host]
+	if !ok {
+		return nil, errTestBase
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Request:    r,
+		Body:       io.NopCloser(&bytes.Buffer{}),
+	}, nil
+}
+
+func TestTransportRoundTripFailover(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		failover       int
+		allowed        int // failovers allowed by the AllowFailover function, -1 when unset
+		status         map[string]int
+		expectStatus   int
+		expectError    bool
+		expectRequests []string
+		expectErrors   []string
+	}{
+		{
+			"next host",
+			2,
+			-1,
+			map[string]int{"host2.example.com": http.StatusServiceUnavailable, "host3.example.com": http.StatusOK},
+			http.StatusOK,
+			false,
+			[]string{"host1.example.com:body", "host2.example.com:body", "host3.example.com:body"},
+			[]string{"host1.example.com", "host2.example.com"},
+		},
+		{
+			"attempts exhausted",
+			1,
+			-1,
+			map[string]int{"host2.example.com": http.StatusServiceUnavailable, "host3.example.com": http.StatusOK},
+			http.StatusServiceUnavailable,
+			false,
+			[]string{"host1.example.com:body", "host2.example.com:body"},
+			[]string{"host1.example.com", "host2.example.com"},
+		},
+		{
+			"not allowed",
+			2,
+			1,
+			map[string]int{"host2.example.com": http.StatusServiceUnavailable, "host3.example.com": http.StatusOK},
+			http.StatusServiceUnavailable,
+			false,
+			[]string{"host1.example.com:body", "host2.example.com:body"},
+			[]string{"host1.example.com", "host2.example.com"},
+		},
+		{
+			"disabled",
+			0,
+			-1,
+			map[string]int{"host2.example.com": http.StatusOK},
+			0,
+			true,
+			[]string{"host1.example.com:body"},
+			[]string{"host1.example.com"},
+		},
+		{
+			"client error",
+			2,
+			-1,
+			map[string]int{"host1.example.com": http.StatusForbidden},
+			http.StatusForbidden,
+			false,
+			[]string{"host1.example.com:body"},
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			selector := &Selector{
+				logger:    zap.NewNop().Sugar(),
+				target:    "host.example.com",
+				failover:  tc.failover,
+				runCh:     make(chan struct{}),
+				startWait: make(chan struct{}),
+			}
+
+			selector.startOnce.Do(func() {})
+
+			if tc.allowed >= 0 {
+				allowed := tc.allowed
+
+				selector.allowFailover = func(_ *http.Request) bool {
+					allowed--
+
+					return allowed >= 0
+				}
+			}
+
+			defer close(selector.runCh)
+
+			for _, name := range []string{"host1.example.com", "host2.example.com", "host3.example.com"} {
+				selector.hosts = append(selector.hosts, newHost(selector, name, "", net.SRV{Target: name}))
+			}
+
+			selector.selected = selector.hosts[0]
+
+			base := &failoverTransport{status: tc.status}
+
+			transport := &Transport{
+				Selector: selector,
+				Base:     base,
+			}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://host.example.com/test-path", io.NopCloser(strings.NewReader("body")))
+			require.NoError(t, err, "no error expected creating request")
+
+			resp, err := transport.RoundTrip(req)
+
+			if tc.expectError {
+				require.Error(t, err, "expected error to be returned")
+			} else {
+				require.NoError(t, err, "no error expected to be returned")
+
+				defer resp.Body.Close() //nolint:errcheck
+
+				assert.Equal(t, tc.expectStatus, resp.StatusCode, "unexpected response status")
+			}
+
+			assert.Equal(t, tc.expectRequests, base.bodies, "unexpected requests made")
+
+			var failed []string
+
+			for _, host := range selector.hosts {
+				if host.Err() != nil {
+					failed = append(failed, host.Host())
+				}
+			}
+
+			assert.Equal(t, tc.expectErrors, failed, "unexpected hosts marked as failed")
+		})
+	}
+}
+
+// hangTransport blocks requests to the hang host until the request context ends.
+type hangTransport struct {
+	hang     string
+	requests []string
+}
+
+func (t *hangTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, r.URL.Host)
+
+	if r.URL.Host == t.hang {
+		<-r.Context().Done()
+
+		return nil, r.Context().Err()
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Request:    r,
+		Body:       io.NopCloser(&bytes.Buffer{}),
+	}, nil
+}
+
+func TestTransportRoundTripTimeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		cause          error
+		expectSelected string
+		expectErrors   []string
+	}{
+		{
+			"attempt timeout",
+			ErrAttemptTimeout,
+			"host2.example.com",
+			[]string{"host1.example.com"},
+		},
+		{
+			"requestor deadline",
+			nil,
+			"host1.example.com",
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			selector := &Selector{
+				logger:    zap.NewNop().Sugar(),
+				target:    "host.example.com",
+				failover:  2,
+				runCh:     make(chan struct{}),
+				startWait: make(chan struct{}),
+			}
+
+			selector.startOnce.Do(func() {})
+
+			defer close(selector.runCh)
+
+			for _, name := range []string{"host1.example.com", "host2.example.com"} {
+				selector.hosts = append(selector.hosts, newHost(selector, name, "", net.SRV{Target: name}))
+			}
+
+			selector.selected = selector.hosts[0]
+
+			base := &hangTransport{hang: "host1.example.com"}
+
+			transport := &Transport{
+				Selector: selector,
+				Base:     base,
+			}
+
+			ctx, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, tc.cause)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://host.example.com/test-path", nil)
+			require.NoError(t, err, "no error expected creating request")
+
+			_, err = transport.RoundTrip(req)
+			require.ErrorIs(t, err, context.DeadlineExceeded, "expected request to time out")
+
+			var failed []string
+
+			for _, host := range selector.hosts {
+				if host.Err() != nil {
+					failed = append(failed, host.Host())
+				}
+			}
+
+			assert.Equal(t, []string{"host1.example.com"}, base.requests, "expected no failover once the request timed out")
+			assert.Equal(t, tc.expectErrors, failed, "unexpected hosts marked as failed")
+			assert.Equal(t, tc.expectSelected, selector.SelectedHost().Host(), "unexpected selected host")
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package selecthost
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
 	"go.uber.org/zap"
@@ -20,6 +22,16 @@ func Logger(logger *zap.SugaredLogger) Option {
 	}
 }
 
+// OnRemove registers a function which is called for each host no longer returned by discovery.
+// A host whose SRV record changed is removed and discovered again.
+func OnRemove(fn func(host Host)) Option {
+	return func(s *Selector) error {
+		s.onRemove = fn
+
+		return nil
+	}
+}
+
 // DiscoveryInterval specifies the interval at which SRV records will be rediscovered.
 // Default: 15m
 func DiscoveryInterval(interval time.Duration) Option {
@@ -69,6 +81,17 @@ func CheckPath(path string) Option {
 	}
 }
 
+// CheckWith sets the checker used to check hosts.
+// When set, CheckScheme and CheckPath are not used.
+// Default: an [HTTPChecker] using the check scheme and path
+func CheckWith(checker Checker) Option {
+	return func(s *Selector) error {
+		s.checker = checker
+
+		return nil
+	}
+}
+
 // CheckCount defines how many checks to run on an endpoint.
 // Default: 5
 func CheckCount(count int) Option {
@@ -119,6 +142,45 @@ func CheckConcurrency(count int) Option {
 	}
 }
 
+// AllowFailover registers a function which is called before a failed request is sent to the next
+// host. When it returns false, the failure is returned instead.
+func AllowFailover(fn func(r *http.Request) bool) Option {
+	return func(s *Selector) error {
+		s.allowFailover = fn
+
+		return nil
+	}
+}
+
+// Failover sets the number of other hosts a request is retried on when the selected host fails
+// with a connection error or a 5xx response. Zero disables failover.
+// Default: 2
+func Failover(attempts int) Option {
+	return func(s *Selector) error {
+		s.failover = max(attempts, 0)
+
+		return nil
+	}
+}
+
+// Balance sets how requests are distributed across discovered hosts.
+// Default: BalanceNone
+func Balance(mode Balancing) Option {
+	return func(s *Selector) error {
+		switch mode {
+		case "":
+			mode = BalanceNone
+		case BalanceNone, BalanceWeighted, BalanceLeastOutstanding:
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidBalancing, mode)
+		}
+
+		s.balancing = mode
+
+		return nil
+	}
+}
+
 // Prefer specifies a preferred host.
 // If the host is not discovered or has an error it will not be used.
 func Prefer(host string) Option {
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -57,9 +59,14 @@ type Selector struct {
 	checkDelay       time.Duration
 	checkTimeout     time.Duration
 	checkConcurrency int
+	checker          Checker
 
 	initTimeout time.Duration
 
+	failover      int
+	allowFailover func(r *http.Request) bool
+	balancing     Balancing
+
 	mu        sync.RWMutex
 	startOnce sync.Once
 	quick     bool
@@ -72,6 +79,8 @@ type Selector struct {
 
 	hosts Hosts
 
+	onRemove func(host Host)
+
 	checkOnce           sync.Once
 	optionalFailureOnce sync.Once
 
@@ -84,6 +93,23 @@ func (s *Selector) Target() string {
 	return s.target
 }
 
+// SelectedHost returns the currently selected host, or nil if no host has been selected.
+func (s *Selector) SelectedHost() Host {
+	return s.getHost()
+}
+
+// Hosts returns a copy of the discovered hosts.
+func (s *Selector) Hosts() Hosts {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(Hosts, len(s.hosts))
+
+	copy(out, s.hosts)
+
+	return out
+}
+
 func (s *Selector) getHost() Host {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -162,6 +188,47 @@ func (s *Selector) Stop() {
 	close(s.runCh)
 }
 
+// UpdateHosts replaces the preferred and fallback hosts and reselects the active host.
+// An empty prefer clears the preferred host. An empty fallback restores the target as the fallback.
+func (s *Selector) UpdateHosts(prefer, fallback string) error {
+	var (
+		preferHost Host
+		err        error
+	)
+
+	if prefer != "" {
+		preferHost, err = ParseHost(s, prefer)
+		if err != nil {
+			return err
+		}
+	}
+
+	if fallback == "" {
+		fallback = s.target
+	}
+
+	fallbackHost, err := ParseHost(s, fallback)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+
+	s.prefer = preferHost
+	s.fallback = fallbackHost
+
+	// Clear stickiness so a newly preferred host is selected immediately.
+	s.stickyUntil = time.Time{}
+
+	s.mu.Unlock()
+
+	s.logger.Infow("Selector hosts updated", "prefer", prefer, "fallback", fallback)
+
+	s.selectHost(context.Background())
+
+	return nil
+}
+
 // selectHost updates the selected host based on the latest host list.
 // Selection is made in the following order.
 //
@@ -292,6 +359,32 @@ func (s *Selector) selectHost(ctx context.Context) {
 	}
 }
 
+// nextHost returns the first host without an error which has not been tried yet, in the order
+// hosts are selected. The fallback host is returned once no discovered host remains.
+// If the fallback host has been tried as well, nil is returned.
+func (s *Selector) nextHost(tried []Host) Host {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wasTried := func(host Host) bool {
+		return slices.ContainsFunc(tried, func(t Host) bool {
+			return t.ID() == host.ID()
+		})
+	}
+
+	for _, host := range s.hosts {
+		if host.Err() == nil && !wasTried(host) {
+			return host
+		}
+	}
+
+	if s.fallback != nil && !wasTried(s.fallback) {
+		return s.fallback
+	}
+
+	return nil
+}
+
 func (s *Selector) discovery() {
 	ticker := time.NewTicker(s.discoveryInterval)
 	defer ticker.Stop()
@@ -381,6 +474,10 @@ func (s *Selector) discoverRecords(ctx context.Context) {
 		span.AddEvent("removed " + host.ID())
 
 		logger.Warnf("Host removed '%s'", host.ID())
+
+		if s.onRemove != nil {
+			s.onRemove(host)
+		}
 	}
 
 	s.checkOnce.Do(func() {
@@ -484,6 +581,9 @@ func NewSelector(target, service, protocol string, options ...Option) (*Selector
 
 		initTimeout: 10 * time.Second,
 
+		failover:  2,
+		balancing: BalanceNone,
+
 		runCh:     make(chan struct{}),
 		startWait: make(chan struct{}),
 	}
@@ -230,6 +230,7 @@ func TestDiscoverRecords(t *testing.T) {
 		records      []*net.SRV
 		expectTarget string
 		expectHosts  []string
+		expectRemove []string
 	}{
 		{
 			"with port",
@@ -241,6 +242,9 @@ func TestDiscoverRecords(t *testing.T) {
 			[]string{
 				"host1.example.com:80:0:0",
 			},
+			[]string{
+				"old1.example.com:80:10:10",
+			},
 		},
 		{
 			"without port",
@@ -252,6 +256,9 @@ func TestDiscoverRecords(t *testing.T) {
 			[]string{
 				"host1.example.com:80:0:0",
 			},
+			[]string{
+				"old1.example.com:80:10:10",
+			},
 		},
 		{
 			"no records",
@@ -259,6 +266,9 @@ func TestDiscoverRecords(t *testing.T) {
 			[]*net.SRV{},
 			"iam.example.com",
 			[]string{},
+			[]string{
+				"old1.example.com:80:10:10",
+			},
 		},
 		{
 			"priority changes",
@@ -272,6 +282,9 @@ func TestDiscoverRecords(t *testing.T) {
 				"new1.example.com:80:10:10",
 				"old1.example.com:80:20:10",
 			},
+			[]string{
+				"old1.example.com:80:10:10",
+			},
 		},
 	}
 
@@ -297,6 +310,12 @@ func TestDiscoverRecords(t *testing.T) {
 				target:   tc.target,
 			}
 
+			removed := []string{}
+
+			selector.onRemove = func(host Host) {
+				removed = append(removed, host.ID())
+			}
+
 			selector.fallback = newHost(selector, "fallback.example.com", "80", net.SRV{})
 			selector.hosts = Hosts{
 				newHost(selector, "old1.example.com", "80", net.SRV{"old1.example.com", 80, 10, 10}),
@@ -313,6 +332,7 @@ func TestDiscoverRecords(t *testing.T) {
 
 			assert.Equal(t, tc.expectTarget, resolver.requestedTarget, "unexpected target queried")
 			assert.Equal(t, tc.expectHosts, hosts, "unexpected hosts returned")
+			assert.Equal(t, tc.expectRemove, removed, "unexpected hosts removed")
 		})
 	}
 }
@@ -538,3 +558,32 @@ func TestSelectorCheckHosts(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectorUpdateHosts(t *testing.T) {
+	t.Parallel()
+
+	selector, err := NewSelector("host1.example.com:443", "test", "tcp")
+	require.NoError(t, err, "no error expected creating selector")
+
+	host2, err := ParseHost(selector, "host2.example.com:443")
+	require.NoError(t, err, "no error expected parsing host")
+
+	selector.hosts = Hosts{selector.fallback, host2}
+	selector.selected = selector.fallback
+	selector.stickyUntil = time.Now().Add(time.Hour)
+
+	require.NoError(t, selector.UpdateHosts("host2.example.com:443", ""), "no error expected updating hosts")
+
+	assert.Equal(t, "host2.example.com:443", net.JoinHostPort(selector.SelectedHost().Host(), selector.SelectedHost().Port()), "expected preferred host to be selected despite stickiness")
+
+	selector.hosts = nil
+
+	require.NoError(t, selector.UpdateHosts("", "host3.example.com:443"), "no error expected updating hosts")
+
+	assert.Nil(t, selector.prefer, "expected preferred host to be cleared")
+	assert.Equal(t, "host3.example.com:443", net.JoinHostPort(selector.SelectedHost().Host(), selector.SelectedHost().Port()), "expected new fallback to be selected")
+
+	require.NoError(t, selector.UpdateHosts("", ""), "no error expected updating hosts")
+
+	assert.Equal(t, "host1.example.com:443", net.JoinHostPort(selector.fallback.Host(), selector.fallback.Port()), "expected target to be restored as fallback")
+}
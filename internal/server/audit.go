@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authorization"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/audit"
+)
+
+// auditCheckAccess records the CheckAccess decision. The credential is never recorded, only its
+// fingerprint and subject.
+func (s *server) auditCheckAccess(ctx context.Context, req *authorization.CheckAccessRequest, resp *authorization.CheckAccessResponse, details checkAccessDetails, err error, latency time.Duration) {
+	event := audit.Event{
+		Subject:               details.subject,
+		CredentialFingerprint: audit.Fingerprint(req.Credential),
+		Mode:                  checkModeAll,
+		Actions:               make([]audit.Action, len(req.Actions)),
+		LatencyMS:             float64(latency) / float64(time.Millisecond),
+	}
+
+	if event.Subject == "" {
+		event.UnverifiedSubject = unverifiedSubject(req.Credential)
+	}
+
+	if details.actionResults != nil {
+		event.Mode = CheckModePerAction
+	}
+
+	if details.degraded {
+		event.Degraded = true
+		event.DegradationPolicy = s.degradation.Mode()
+		event.DecisionAgeMS = float64(details.decisionAge) / float64(time.Millisecond)
+	}
+
+	for i, a := range req.Actions {
+		event.Actions[i] = audit.Action{
+			Action:     a.Action,
+			ResourceID: a.ResourceId,
+		}
+
+		if i < len(details.actionResults) {
+			event.Actions[i].Outcome = auditOutcome(details.actionResults[i])
+		}
+	}
+
+	switch {
+	case err != nil:
+		event.Outcome = audit.OutcomeError
+		event.Error = audit.Redact(status.Convert(err).Message(), req.Credential)
+	case resp.GetResult() == authorization.CheckAccessResponse_RESULT_ALLOWED:
+		event.Outcome = audit.OutcomeAllowed
+	default:
+		event.Outcome = audit.OutcomeDenied
+	}
+
+	s.auditor.Record(ctx, event)
+}
+
+// auditOutcome converts a CheckAccess result name into an audit outcome.
+func auditOutcome(result string) string {
+	if result == authorization.CheckAccessResponse_RESULT_ALLOWED.String() {
+		return audit.OutcomeAllowed
+	}
+
+	return audit.OutcomeDenied
+}
+
+// unverifiedSubject returns the subject claim of the credential without verifying it, for credentials
+// which were not validated locally. Empty is returned for credentials which are not JWTs.
+func unverifiedSubject(credential string) string {
+	token, _, err := gojwt.NewParser().ParseUnverified(credential, gojwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+
+	sub, err := token.Claims.GetSubject()
+	if err != nil {
+		return ""
+	}
+
+	return sub
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authorization"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/otelx"
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+)
+
+const (
+	// CheckModeMetadataKey is the request metadata key clients set to select the CheckAccess evaluation mode.
+	CheckModeMetadataKey = "iam-runtime-check-mode"
+
+	// CheckModePerAction is the CheckAccess evaluation mode which evaluates each action individually.
+	// The result for each action is returned in the [ActionResultsMetadataKey] response header.
+	CheckModePerAction = "per-action"
+
+	// ActionResultsMetadataKey is the response header metadata key containing the result of each action
+	// when the per-action evaluation mode is used. Values are listed in the same order as the request actions.
+	ActionResultsMetadataKey = "iam-runtime-action-results"
+
+	// DegradedMetadataKey is the response header metadata key set to the degradation policy when the
+	// decision was made by the degradation policy because permissions-api was unavailable.
+	DegradedMetadataKey = "iam-runtime-degraded"
+
+	checkModeAll = "all"
+)
+
+// checkAccessDetails describes how a CheckAccess decision was made, for auditing.
+type checkAccessDetails struct {
+	// subject is the subject of the credential when it was validated by prevalidation.
+	subject string
+
+	// actionResults is the result of each action when the actions were evaluated individually.
+	actionResults []string
+
+	// degraded is set when the decision was made by the degradation policy.
+	degraded bool
+
+	// decisionAge is the age of the decision served by the stale degradation policy.
+	decisionAge time.Duration
+}
+
+// perActionRequested returns true when the caller requested the per-action evaluation mode.
+func perActionRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, mode := range md.Get(CheckModeMetadataKey) {
+		if strings.EqualFold(mode, CheckModePerAction) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkAccessPerAction evaluates each action individually. The overall result is allowed only when
+// every action is allowed, while the result of each action is returned in the response header.
+func (s *server) checkAccessPerAction(ctx context.Context, credential, subject string, actions []permissions.RequestAction) (*authorization.CheckAccessResponse, checkAccessDetails, error) {
+	span := trace.SpanFromContext(ctx)
+
+	span.SetAttributes(attribute.String("checkaccess.mode", CheckModePerAction))
+
+	results, err := s.permClient.CheckActions(ctx, credential, actions)
+	if err != nil {
+		if out, details, ok := s.checkAccessDegraded(ctx, credential, subject, actions, true, err); ok {
+			return out, details, nil
+		}
+
+		return nil, checkAccessDetails{}, checkAccessError(span, err)
+	}
+
+	recorded := make([]bool, len(results))
+
+	for i, actionResult := range results {
+		recorded[i] = actionResult.Allowed
+	}
+
+	s.degradation.Record(credential, actions, recorded)
+
+	var (
+		result  = authorization.CheckAccessResponse_RESULT_ALLOWED
+		values  = make([]string, len(results))
+		denied  = make([]string, 0, len(results))
+		allowed int
+	)
+
+	for i, actionResult := range results {
+		if actionResult.Allowed {
+			values[i] = authorization.CheckAccessResponse_RESULT_ALLOWED.String()
+
+			allowed++
+
+			continue
+		}
+
+		values[i] = authorization.CheckAccessResponse_RESULT_DENIED.String()
+
+		denied = append(denied, actionResult.Action+" "+actionResult.ResourceID)
+
+		result = authorization.CheckAccessResponse_RESULT_DENIED
+	}
+
+	span.SetAttributes(
+		attribute.StringSlice("checkaccess.action_results", values),
+		attribute.StringSlice("checkaccess.denied_actions", denied),
+		attribute.Int("checkaccess.actions.allowed", allowed),
+		attribute.Int("checkaccess.actions.denied", len(denied)),
+	)
+
+	if err := grpc.SetHeader(ctx, metadata.MD{ActionResultsMetadataKey: values}); err != nil {
+		span.RecordError(err)
+
+		s.logger.Warnw("failed to set action results header", "error", err)
+	}
+
+	if result == authorization.CheckAccessResponse_RESULT_ALLOWED {
+		span.AddEvent("allowed")
+	} else {
+		span.AddEvent("denied")
+	}
+
+	out := &authorization.CheckAccessResponse{
+		Result: result,
+	}
+
+	return out, checkAccessDetails{actionResults: values}, nil
+}
+
+// checkAccessDegraded decides the request with the degradation policy when permissions-api is
+// unavailable, that is it could not be reached, responded with a 5xx status code or its circuit
+// is open. False is returned when the request must fail closed, which is the case for any other
+// failure, such as a 4xx response or a disabled client, when the request was canceled, when the
+// credential was not validated locally or when the policy cannot decide every action.
+func (s *server) checkAccessDegraded(ctx context.Context, credential, subject string, actions []permissions.RequestAction, perAction bool, cause error) (*authorization.CheckAccessResponse, checkAccessDetails, bool) {
+	unavailable := errors.Is(cause, permissions.ErrUnavailable) || errors.Is(cause, permissions.ErrCircuitOpen)
+
+	if !unavailable || ctx.Err() != nil {
+		return nil, checkAccessDetails{}, false
+	}
+
+	// Without permissions-api, nothing else verifies the credential.
+	if subject == "" {
+		trace.SpanFromContext(ctx).AddEvent("degradation skipped, credential not validated")
+
+		return nil, checkAccessDetails{}, false
+	}
+
+	decision, ok := s.degradation.Decide(credential, actions)
+	if !ok {
+		return nil, checkAccessDetails{}, false
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	var (
+		result = authorization.CheckAccessResponse_RESULT_ALLOWED
+		values = make([]string, len(decision.Allowed))
+	)
+
+	for i, allowed := range decision.Allowed {
+		if allowed {
+			values[i] = authorization.CheckAccessResponse_RESULT_ALLOWED.String()
+
+			continue
+		}
+
+		values[i] = authorization.CheckAccessResponse_RESULT_DENIED.String()
+
+		result = authorization.CheckAccessResponse_RESULT_DENIED
+	}
+
+	span.RecordError(cause)
+	span.SetAttributes(
+		attribute.Bool("checkaccess.degraded", true),
+		attribute.String("checkaccess.degradation.policy", s.degradation.Mode()),
+		attribute.Int64("checkaccess.degradation.decision_age_ms", decision.Age.Milliseconds()),
+	)
+	span.AddEvent("degraded "+strings.ToLower(strings.TrimPrefix(result.String(), "RESULT_")), trace.WithAttributes(
+		attribute.String("checkaccess.degradation.cause", cause.Error()),
+	))
+
+	s.requestLogger(ctx).Warnw("permissions-api unavailable, request decided by degradation policy",
+		"policy", s.degradation.Mode(),
+		"result", result.String(),
+		"decision_age", decision.Age,
+		"error", cause,
+		otelx.ContextField(ctx),
+	)
+
+	md := metadata.MD{DegradedMetadataKey: []string{s.degradation.Mode()}}
+
+	details := checkAccessDetails{
+		degraded:    true,
+		decisionAge: decision.Age,
+	}
+
+	if perAction {
+		md.Set(ActionResultsMetadataKey, values...)
+
+		span.SetAttributes(attribute.StringSlice("checkaccess.action_results", values))
+
+		details.actionResults = values
+	}
+
+	if err := grpc.SetHeader(ctx, md); err != nil {
+		span.RecordError(err)
+
+		s.logger.Warnw("failed to set degraded header", "error", err)
+	}
+
+	out := &authorization.CheckAccessResponse{
+		Result: result,
+	}
+
+	return out, details, true
+}
@@ -0,0 +1,519 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authorization"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/audit"
+	"go.infratographer.com/iam-runtime-infratographer/internal/degradation"
+	"go.infratographer.com/iam-runtime-infratographer/internal/introspection"
+	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+	"go.infratographer.com/iam-runtime-infratographer/internal/revocation"
+)
+
+// testPermissionsClient returns the configured error, or the configured results when no error is set.
+type testPermissionsClient struct {
+	permissions.Client
+
+	err     error
+	results []bool
+}
+
+func (c *testPermissionsClient) CheckAccess(_ context.Context, _ string, _ []permissions.RequestAction) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	for _, allowed := range c.results {
+		if !allowed {
+			return permissions.ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+func (c *testPermissionsClient) CheckActions(_ context.Context, _ string, actions []permissions.RequestAction) ([]permissions.ActionResult, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	out := make([]permissions.ActionResult, len(actions))
+
+	for i, action := range actions {
+		out[i] = permissions.ActionResult{
+			RequestAction: action,
+			Allowed:       c.results[i],
+		}
+	}
+
+	return out, nil
+}
+
+// testAuditor keeps recorded events.
+type testAuditor struct {
+	events []audit.Event
+}
+
+func (a *testAuditor) Record(_ context.Context, event audit.Event) {
+	a.events = append(a.events, event)
+}
+
+func (a *testAuditor) Close() error {
+	return nil
+}
+
+func TestCheckAccessDegraded(t *testing.T) {
+	t.Parallel()
+
+	errUnavailable := fmt.Errorf("%w: %w: status code %d", permissions.ErrUnexpectedResponse, permissions.ErrUnavailable, http.StatusServiceUnavailable)
+
+	req := &authorization.CheckAccessRequest{
+		Credential: "token",
+		Actions: []*authorization.AccessRequestAction{
+			{Action: "loadbalancer_get", ResourceId: "loadbal-abc"},
+			{Action: "loadbalancer_update", ResourceId: "loadbal-abc"},
+		},
+	}
+
+	revocationChecker, err := revocation.NewChecker(context.Background(), revocation.Config{}, nil, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating revocation checker")
+
+	perAction := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CheckModeMetadataKey, CheckModePerAction))
+
+	testCases := []struct {
+		name         string
+		config       degradation.Config
+		ctx          context.Context
+		record       bool
+		err          error
+		expectCode   codes.Code
+		expectResult authorization.CheckAccessResponse_Result
+		validator    *testValidator
+	}{
+		{
+			"fail closed",
+			degradation.Config{Mode: degradation.ModeFailClosed},
+			perAction, true, errUnavailable,
+			codes.Unavailable, 0,
+			nil,
+		},
+		{
+			"stale",
+			degradation.Config{Mode: degradation.ModeStale, MaxAge: time.Minute},
+			perAction, true, errUnavailable,
+			codes.OK, authorization.CheckAccessResponse_RESULT_DENIED,
+			nil,
+		},
+		{
+			"stale not recorded",
+			degradation.Config{Mode: degradation.ModeStale, MaxAge: time.Minute},
+			perAction, false, errUnavailable,
+			codes.Unavailable, 0,
+			nil,
+		},
+		{
+			"stale unauthenticated",
+			degradation.Config{Mode: degradation.ModeStale, MaxAge: time.Minute},
+			perAction, true, permissions.ErrUnauthenticated,
+			codes.InvalidArgument, 0,
+			nil,
+		},
+		{
+			"stale client error",
+			degradation.Config{Mode: degradation.ModeStale, MaxAge: time.Minute},
+			perAction, true, fmt.Errorf("%w: status code %d", permissions.ErrUnexpectedResponse, http.StatusBadRequest),
+			codes.Unavailable, 0,
+			nil,
+		},
+		{
+			"stale circuit open",
+			degradation.Config{Mode: degradation.ModeStale, MaxAge: time.Minute},
+			perAction, true, permissions.ErrCircuitOpen,
+			codes.OK, authorization.CheckAccessResponse_RESULT_DENIED,
+			nil,
+		},
+		{
+			"allowlist",
+			degradation.Config{Mode: degradation.ModeAllowlist, Allowlist: []string{"loadbalancer_get", "loadbalancer_update"}},
+			context.Background(), false, errUnavailable,
+			codes.OK, authorization.CheckAccessResponse_RESULT_ALLOWED,
+			nil,
+		},
+		{
+			"allowlist invalid credential",
+			degradation.Config{Mode: degradation.ModeAllowlist, Allowlist: []string{"loadbalancer_get", "loadbalancer_update"}},
+			context.Background(), false, errUnavailable,
+			codes.InvalidArgument, 0,
+			&testValidator{err: gojwt.ErrTokenExpired},
+		},
+		{
+			"allowlist credential not validated",
+			degradation.Config{Mode: degradation.ModeAllowlist, Allowlist: []string{"loadbalancer_get", "loadbalancer_update"}},
+			context.Background(), false, errUnavailable,
+			codes.Unavailable, 0,
+			&testValidator{err: jwt.ErrServiceDisabled},
+		},
+		{
+			"allowlist client error",
+			degradation.Config{Mode: degradation.ModeAllowlist, Allowlist: []string{"loadbalancer_get", "loadbalancer_update"}},
+			context.Background(), false, fmt.Errorf("%w: status code %d", permissions.ErrUnexpectedResponse, http.StatusTooManyRequests),
+			codes.Unavailable, 0,
+			nil,
+		},
+		{
+			"allowlist service disabled",
+			degradation.Config{Mode: degradation.ModeAllowlist, Allowlist: []string{"loadbalancer_get", "loadbalancer_update"}},
+			context.Background(), false, permissions.ErrServiceDisabled,
+			codes.Unavailable, 0,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := degradation.NewPolicy(tc.config)
+			require.NoError(t, err, "no error expected creating policy")
+
+			permClient := &testPermissionsClient{results: []bool{true, false}}
+			auditor := &testAuditor{}
+
+			validator := tc.validator
+			if validator == nil {
+				validator = &testValidator{subject: "idntusr-abc"}
+			}
+
+			s := &server{
+				logger:      zap.NewNop().Sugar(),
+				validator:   validator,
+				permClient:  permClient,
+				revocation:  revocationChecker,
+				auditor:     auditor,
+				degradation: policy,
+
+				prevalidateCredentials: true,
+			}
+
+			if tc.record {
+				resp, err := s.CheckAccess(tc.ctx, req)
+				require.NoError(t, err, "no error expected recording decisions")
+				require.Equal(t, authorization.CheckAccessResponse_RESULT_DENIED, resp.GetResult(), "unexpected recorded result")
+			}
+
+			permClient.err = tc.err
+
+			resp, err := s.CheckAccess(tc.ctx, req)
+
+			require.Equal(t, tc.expectCode, status.Code(err), "unexpected status code")
+
+			event := auditor.events[len(auditor.events)-1]
+
+			if tc.expectCode != codes.OK {
+				assert.False(t, event.Degraded, "expected failed decision to not be degraded")
+
+				return
+			}
+
+			assert.Equal(t, tc.expectResult, resp.GetResult(), "unexpected result")
+			assert.True(t, event.Degraded, "expected audit event to be marked degraded")
+			assert.Equal(t, policy.Mode(), event.DegradationPolicy, "unexpected audit degradation policy")
+		})
+	}
+}
+
+// testValidator returns the configured subject and error for every token.
+type testValidator struct {
+	jwt.Validator
+
+	subject string
+	err     error
+}
+
+func (v *testValidator) ValidateToken(_ string) (string, map[string]any, error) {
+	return v.subject, map[string]any{"sub": v.subject}, v.err
+}
+
+func TestCheckAccessAuditSubject(t *testing.T) {
+	t.Parallel()
+
+	credential, err := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": "idntusr-claimed"}).SignedString([]byte("test-key"))
+	require.NoError(t, err, "no error expected signing token")
+
+	revocationChecker, err := revocation.NewChecker(context.Background(), revocation.Config{}, nil, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating revocation checker")
+
+	testCases := []struct {
+		name             string
+		prevalidate      bool
+		validator        *testValidator
+		expectSubject    string
+		expectUnverified string
+	}{
+		{
+			"prevalidated",
+			true,
+			&testValidator{subject: "idntusr-validated"},
+			"idntusr-validated",
+			"",
+		},
+		{
+			"not prevalidated",
+			false,
+			&testValidator{subject: "idntusr-validated"},
+			"",
+			"idntusr-claimed",
+		},
+		{
+			"jwt service disabled",
+			true,
+			&testValidator{err: jwt.ErrServiceDisabled},
+			"",
+			"idntusr-claimed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := degradation.NewPolicy(degradation.Config{Mode: degradation.ModeFailClosed})
+			require.NoError(t, err, "no error expected creating policy")
+
+			auditor := &testAuditor{}
+
+			s := &server{
+				logger:                 zap.NewNop().Sugar(),
+				validator:              tc.validator,
+				revocation:             revocationChecker,
+				permClient:             &testPermissionsClient{results: []bool{true}},
+				auditor:                auditor,
+				degradation:            policy,
+				prevalidateCredentials: tc.prevalidate,
+			}
+
+			_, err = s.CheckAccess(context.Background(), &authorization.CheckAccessRequest{
+				Credential: credential,
+				Actions:    []*authorization.AccessRequestAction{{Action: "loadbalancer_get", ResourceId: "loadbal-abc"}},
+			})
+			require.NoError(t, err, "no error expected")
+
+			require.Len(t, auditor.events, 1, "expected decision to be audited")
+
+			assert.Equal(t, tc.expectSubject, auditor.events[0].Subject, "unexpected subject")
+			assert.Equal(t, tc.expectUnverified, auditor.events[0].UnverifiedSubject, "unexpected unverified subject")
+		})
+	}
+}
+
+func TestNewServerDegradation(t *testing.T) {
+	t.Parallel()
+
+	permClient, err := permissions.NewClient(permissions.Config{Disable: true}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating permissions client")
+
+	testCases := []struct {
+		name        string
+		mode        string
+		prevalidate bool
+		expectError error
+	}{
+		{"fail closed", degradation.ModeFailClosed, false, nil},
+		{"allowlist", degradation.ModeAllowlist, true, nil},
+		{"allowlist without prevalidation", degradation.ModeAllowlist, false, ErrDegradationWithoutPrevalidation},
+		{"stale without prevalidation", degradation.ModeStale, false, ErrDegradationWithoutPrevalidation},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := degradation.NewPolicy(degradation.Config{
+				Mode:      tc.mode,
+				MaxAge:    time.Minute,
+				Allowlist: []string{"loadbalancer_get"},
+			})
+			require.NoError(t, err, "no error expected creating policy")
+
+			config := Config{
+				SocketPath:             filepath.Join(t.TempDir(), "runtime.sock"),
+				PrevalidateCredentials: tc.prevalidate,
+			}
+
+			srv, err := NewServer(config, nil, nil, permClient, nil, nil, nil, policy, nil, zap.NewNop().Sugar())
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+
+			srv.Stop()
+		})
+	}
+}
+
+// unexpectedPermissionsClient fails the test when permissions-api would be called.
+type unexpectedPermissionsClient struct {
+	permissions.Client
+
+	t *testing.T
+}
+
+func (c *unexpectedPermissionsClient) CheckAccess(_ context.Context, _ string, _ []permissions.RequestAction) error {
+	c.t.Error("unexpected permissions-api request")
+
+	return nil
+}
+
+// testIntrospector returns the configured subject and error for every token.
+type testIntrospector struct {
+	subject string
+	err     error
+}
+
+func (i *testIntrospector) Introspect(_ context.Context, _ string) (string, map[string]any, error) {
+	return i.subject, map[string]any{"sub": i.subject}, i.err
+}
+
+// testRevocationChecker returns the configured error for every token.
+type testRevocationChecker struct {
+	err error
+}
+
+func (c *testRevocationChecker) CheckRevoked(_ context.Context, _ map[string]any) error {
+	return c.err
+}
+
+func TestCheckAccessPrevalidateCredential(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		prevalidate   bool
+		validatorErr  error
+		introspectErr error
+		revokedErr    error
+		expectCalled  bool
+		expectCode    codes.Code
+	}{
+		{
+			name:         "disabled",
+			validatorErr: gojwt.ErrTokenExpired,
+			expectCalled: true,
+			expectCode:   codes.OK,
+		},
+		{
+			name:         "valid",
+			prevalidate:  true,
+			expectCalled: true,
+			expectCode:   codes.OK,
+		},
+		{
+			name:         "expired",
+			prevalidate:  true,
+			validatorErr: fmt.Errorf("%w: %w", gojwt.ErrTokenInvalidClaims, gojwt.ErrTokenExpired),
+			expectCode:   codes.InvalidArgument,
+		},
+		{
+			name:         "unknown issuer",
+			prevalidate:  true,
+			validatorErr: jwt.ErrUnknownIssuer,
+			expectCode:   codes.InvalidArgument,
+		},
+		{
+			name:         "invalid signature",
+			prevalidate:  true,
+			validatorErr: gojwt.ErrTokenSignatureInvalid,
+			expectCode:   codes.InvalidArgument,
+		},
+		{
+			name:          "malformed without introspection",
+			prevalidate:   true,
+			validatorErr:  gojwt.ErrTokenMalformed,
+			introspectErr: introspection.ErrServiceDisabled,
+			expectCode:    codes.InvalidArgument,
+		},
+		{
+			name:          "introspection inactive",
+			prevalidate:   true,
+			validatorErr:  gojwt.ErrTokenMalformed,
+			introspectErr: introspection.ErrTokenInactive,
+			expectCode:    codes.InvalidArgument,
+		},
+		{
+			name:          "introspection failed",
+			prevalidate:   true,
+			validatorErr:  gojwt.ErrTokenMalformed,
+			introspectErr: introspection.ErrIntrospectionFailed,
+			expectCode:    codes.Unavailable,
+		},
+		{
+			name:        "revoked",
+			prevalidate: true,
+			revokedErr:  revocation.ErrTokenRevoked,
+			expectCode:  codes.InvalidArgument,
+		},
+		{
+			name:         "jwt service disabled",
+			prevalidate:  true,
+			validatorErr: jwt.ErrServiceDisabled,
+			expectCalled: true,
+			expectCode:   codes.OK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := degradation.NewPolicy(degradation.Config{Mode: degradation.ModeFailClosed})
+			require.NoError(t, err, "no error expected creating policy")
+
+			var permClient permissions.Client = &unexpectedPermissionsClient{t: t}
+
+			if tc.expectCalled {
+				permClient = &testPermissionsClient{results: []bool{true}}
+			}
+
+			s := &server{
+				logger:                 zap.NewNop().Sugar(),
+				validator:              &testValidator{subject: "idntusr-abc", err: tc.validatorErr},
+				introspector:           &testIntrospector{subject: "idntusr-abc", err: tc.introspectErr},
+				revocation:             &testRevocationChecker{err: tc.revokedErr},
+				permClient:             permClient,
+				auditor:                &testAuditor{},
+				degradation:            policy,
+				prevalidateCredentials: tc.prevalidate,
+			}
+
+			resp, err := s.CheckAccess(context.Background(), &authorization.CheckAccessRequest{
+				Credential: "token",
+				Actions:    []*authorization.AccessRequestAction{{Action: "loadbalancer_get", ResourceId: "loadbal-abc"}},
+			})
+
+			require.Equal(t, tc.expectCode, status.Code(err), "unexpected status code")
+
+			if tc.expectCode == codes.OK {
+				assert.Equal(t, authorization.CheckAccessResponse_RESULT_ALLOWED, resp.GetResult(), "unexpected result")
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
 )
 
@@ -8,10 +10,127 @@ import (
 type Config struct {
 	SocketPath    string
 	HealthAddress string
+
+	// SocketMode is the octal file mode the socket is set to, such as 0660.
+	// The mode is left as created when empty.
+	SocketMode string
+
+	// SocketUID is the user ID the socket is owned by. The owner is left unchanged when unset or negative.
+	// Default: unchanged
+	SocketUID *int
+
+	// SocketGID is the group ID the socket is owned by. The group is left unchanged when unset or negative.
+	// Default: unchanged
+	SocketGID *int
+
+	// AllowedPeerUIDs restricts socket callers to processes running as one of the listed users,
+	// or one of the AllowedPeerGIDs groups, using SO_PEERCRED. Linux only. Can not be combined
+	// with a TCP listener.
+	AllowedPeerUIDs []int
+
+	// AllowedPeerGIDs restricts socket callers to processes running as one of the listed groups,
+	// or one of the AllowedPeerUIDs users, using SO_PEERCRED. Linux only. Can not be combined
+	// with a TCP listener. Only the primary group of the peer process is checked, supplementary
+	// groups are not.
+	AllowedPeerGIDs []int
+
+	// TCP configures a TCP listener serving the IAM runtime API, in addition to the socket.
+	TCP TCPConfig
+
+	// HTTPAddress is the listen address of the HTTP server which serves metrics at /metrics and
+	// health probes at /livez and /readyz.
+	//
+	// Default: :4785
+	HTTPAddress string
+
+	// Health configures the background health checks.
+	Health HealthConfig
+
+	// DrainPeriod is how long health checks report NOT_SERVING during shutdown before the server
+	// stops accepting requests, giving clients and load balancers time to stop sending requests.
+	//
+	// Default: 5s
+	DrainPeriod time.Duration
+
+	// ShutdownTimeout is how long in-flight requests are given to complete during shutdown before
+	// the server is stopped forcefully.
+	//
+	// Default: 30s
+	ShutdownTimeout time.Duration
+
+	// PrevalidateCredentials validates CheckAccess credentials locally with the JWT validator
+	// before forwarding the request to permissions-api. It is required by degradation policies
+	// other than fail-closed.
+	PrevalidateCredentials bool
+}
+
+// HealthConfig represents the configuration of the background health checks.
+type HealthConfig struct {
+	// Interval is how often each service is health checked.
+	//
+	// Default: 10s
+	Interval time.Duration
+
+	// Timeout is how long a single health check may take before the service is considered unhealthy.
+	//
+	// Default: 5s
+	Timeout time.Duration
+
+	// NonCritical lists the services (server, jwt, permissions, permissionsCircuit, events and
+	// accessToken) which are reported individually but do not affect the overall health status.
+	NonCritical []string
+}
+
+// TCPConfig represents the configuration of the TCP listener.
+type TCPConfig struct {
+	// Address is the listen address of the TCP listener. The TCP listener is disabled when empty.
+	Address string
+
+	// Insecure serves the TCP listener without TLS. Requires NoClientAuth.
+	Insecure bool
+
+	// NoClientAuth allows clients to connect to the TCP listener without a client certificate.
+	// Client certificates verified with TLS.ClientCAFile are required unless set. The identity
+	// service, which returns the runtime's access token, is not served to unauthenticated clients.
+	NoClientAuth bool
+
+	// TLS configures the TCP listener certificates.
+	TLS TLSConfig
+}
+
+// TLSConfig represents a TLS certificate configuration. Certificates are reloaded when the files change.
+type TLSConfig struct {
+	// CertFile is the path to the PEM encoded server certificate.
+	CertFile string
+
+	// KeyFile is the path to the PEM encoded server private key.
+	KeyFile string
+
+	// ClientCAFile is the path to PEM encoded CA certificates client certificates are verified with.
+	// Client certificates are required when set. Required unless TCPConfig.NoClientAuth is set.
+	ClientCAFile string
 }
 
 // AddFlags sets the command line flags for the IAM runtime server.
 func AddFlags(flags *pflag.FlagSet) {
 	flags.String("server.socketpath", "", "gRPC server socket path")
+	flags.String("server.socketmode", "", "octal file mode of the gRPC server socket, such as 0660")
+	flags.Int("server.socketuid", -1, "user id owning the gRPC server socket")
+	flags.Int("server.socketgid", -1, "group id owning the gRPC server socket")
+	flags.IntSlice("server.allowedpeeruids", nil, "user ids allowed to call the gRPC server socket")
+	flags.IntSlice("server.allowedpeergids", nil, "group ids allowed to call the gRPC server socket")
+	flags.String("server.tcp.address", "", "gRPC server TCP listen address")
+	flags.Bool("server.tcp.insecure", false, "serve the gRPC TCP listener without TLS (requires server.tcp.noclientauth)")
+	flags.Bool("server.tcp.noclientauth", false, "allow gRPC TCP clients without a client certificate, the identity service is not served to them")
+	flags.String("server.tcp.tls.certfile", "", "path to the gRPC TCP listener TLS certificate")
+	flags.String("server.tcp.tls.keyfile", "", "path to the gRPC TCP listener TLS private key")
+	flags.String("server.tcp.tls.clientcafile", "", "path to the CA certificates client certificates are verified with")
 	flags.String("server.healthaddress", ":4784", "gRPC health server listen address")
+	flags.String("server.httpaddress", ":4785", "HTTP server listen address for metrics and health probes")
+	flags.Duration("server.health.interval", defaultHealthInterval, "how often each service is health checked")
+	flags.Duration("server.health.timeout", defaultHealthTimeout, "how long a health check may take before the service is considered unhealthy")
+	flags.StringSlice("server.health.noncritical", nil, "services which do not affect the overall health status")
+	flags.Duration("server.drainperiod", 5*time.Second, "how long health checks report not serving before the server stops accepting requests")           //nolint:mnd
+	flags.Duration("server.shutdowntimeout", 30*time.Second, "how long in-flight requests are given to complete before the server is stopped forcefully") //nolint:mnd
+	flags.Bool("server.prevalidatecredentials", false, "validate CheckAccess credentials locally before calling permissions-api")
 }
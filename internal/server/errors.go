@@ -9,4 +9,36 @@ var (
 	ErrMissingValue = errors.New("missing value")
 	// ErrServerNotRunning is returned by the server health check when the server is not running.
 	ErrServerNotRunning = errors.New("server not running")
+	// ErrServerShuttingDown is returned by the server health check once shutdown has started.
+	ErrServerShuttingDown = errors.New("server shutting down")
+	// ErrShutdownTimeout is returned when the server did not stop gracefully within the shutdown timeout.
+	ErrShutdownTimeout = errors.New("server did not stop gracefully within the shutdown timeout")
+	// ErrUnknownHealthService is returned when a non-critical health service is not a known service.
+	ErrUnknownHealthService = errors.New("unknown health service")
+	// ErrNoListeners is returned when neither a socket path nor a TCP address is configured.
+	ErrNoListeners = errors.New("server.socketpath or server.tcp.address is required")
+	// ErrTLSCertificateRequired is returned when the TCP listener is configured without a certificate
+	// and insecure serving was not requested.
+	ErrTLSCertificateRequired = errors.New("tls certificate and key are required for the tcp listener")
+	// ErrTLSClientCARequired is returned when the TCP listener is configured without client
+	// certificate verification and unauthenticated clients were not explicitly allowed.
+	ErrTLSClientCARequired = errors.New("tls client ca is required for the tcp listener unless server.tcp.noclientauth is set")
+	// ErrPeerAllowlistWithTCP is returned when allowed peer ids are combined with a TCP listener,
+	// as peer credentials are only available for socket callers.
+	ErrPeerAllowlistWithTCP = errors.New("allowed peer ids can not be combined with a tcp listener")
+	// ErrSocketPathNotSocket is returned when the socket path exists and is not a socket.
+	ErrSocketPathNotSocket = errors.New("socket path exists and is not a socket")
+	// ErrInvalidSocketMode is returned when the socket mode is not an octal file mode.
+	ErrInvalidSocketMode = errors.New("invalid socket mode")
+	// ErrInvalidPeerID is returned when an allowed peer user or group ID is negative.
+	ErrInvalidPeerID = errors.New("allowed peer ids must not be negative")
+	// ErrPeerCredentialsUnsupported is returned when socket peer credentials are not supported on the platform.
+	ErrPeerCredentialsUnsupported = errors.New("socket peer credentials are not supported on this platform")
+	// ErrPeerNotAllowed is returned when a socket peer is not in the allowed users or groups.
+	ErrPeerNotAllowed = errors.New("socket peer not allowed")
+	// ErrTLSClientCAInvalid is returned when the client CA file contains no certificates.
+	ErrTLSClientCAInvalid = errors.New("no certificates found in tls client ca")
+	// ErrDegradationWithoutPrevalidation is returned when a degradation policy other than fail-closed
+	// is configured without credential prevalidation.
+	ErrDegradationWithoutPrevalidation = errors.New("degradation policies other than fail-closed require server.prevalidatecredentials")
 )
@@ -2,15 +2,15 @@ package server
 
 import (
 	"context"
-	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
-const serviceHealthCheckTimeout = 30 * time.Second
-
 // HealthChecker defines a health checker service.
 type HealthChecker interface {
 	HealthCheck(ctx context.Context) error
@@ -19,44 +19,70 @@ type HealthChecker interface {
 // HealthChecks specifies a service name and the health check function to call.
 type HealthChecks map[string]HealthChecker
 
+// HealthCheckFunc is a function implementing HealthChecker.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck calls the function.
+func (f HealthCheckFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
 var _ health.HealthServer = (*server)(nil)
 
-// Check implements Health service checks.
+// Check implements Health service checks. The status last reported by the background health checks
+// of the requested service is returned. An empty service returns the overall status, which is serving
+// when every critical service is serving.
 func (s *server) Check(ctx context.Context, in *health.HealthCheckRequest) (*health.HealthCheckResponse, error) {
 	span := trace.SpanFromContext(ctx)
 
-	s.logger.Info("received HealthCheck request")
-
-	status := health.HealthCheckResponse_SERVING
+	s.logger.Debugw("received HealthCheck request", "service", in.GetService())
 
-	if s.healthChecks != nil {
-		for name, svc := range s.healthChecks {
-			if in.GetService() != "" && in.GetService() != name {
-				continue
-			}
-
-			chkCtx, cancel := context.WithTimeout(ctx, serviceHealthCheckTimeout)
-			defer cancel()
-
-			if err := svc.HealthCheck(chkCtx); err == nil {
-				s.logger.Debugf("Service %s healthy", name)
-			} else {
-				s.logger.Errorf("Service %s unhealthy: %s", name, err.Error())
-
-				status = health.HealthCheckResponse_NOT_SERVING
-
-				break
-			}
-		}
+	healthStatus, ok := s.health.status(in.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", in.GetService())
 	}
 
 	span.SetAttributes(
-		attribute.String("healthcheck.outcome", status.String()),
+		attribute.String("healthcheck.service", in.GetService()),
+		attribute.String("healthcheck.outcome", healthStatus.String()),
 	)
 
 	resp := &health.HealthCheckResponse{
-		Status: status,
+		Status: healthStatus,
 	}
 
 	return resp, nil
 }
+
+// Watch implements Health service watches. The current status of the requested service is sent
+// followed by every status change. Unknown services are reported as SERVICE_UNKNOWN.
+func (s *server) Watch(in *health.HealthCheckRequest, stream grpc.ServerStreamingServer[health.HealthCheckResponse]) error {
+	updates, stop := s.health.watch(in.GetService())
+	defer stop()
+
+	send := func(healthStatus health.HealthCheckResponse_ServingStatus) error {
+		return stream.Send(&health.HealthCheckResponse{Status: healthStatus})
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-s.health.done:
+			// Deliver the final status before ending the watch.
+			select {
+			case healthStatus := <-updates:
+				if err := send(healthStatus); err != nil {
+					return err
+				}
+			default:
+			}
+
+			return status.Error(codes.Unavailable, ErrServerShuttingDown.Error())
+		case healthStatus := <-updates:
+			if err := send(healthStatus); err != nil {
+				return err
+			}
+		}
+	}
+}
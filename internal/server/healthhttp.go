@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+// healthResponse is the body of the HTTP health endpoints.
+type healthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]componentHealth `json:"components"`
+}
+
+// componentHealth reports the last health check of a component.
+type componentHealth struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Stalled   bool      `json:"stalled,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	LastCheck time.Time `json:"last_check,omitzero"`
+}
+
+// handleLivez reports whether the process is live. Component failures, such as JWKS or
+// permissions-api outages, do not fail liveness as restarting would not resolve them. Liveness only
+// fails when a health check is stalled, indicating the process is stuck.
+func (s *server) handleLivez(w http.ResponseWriter, _ *http.Request) {
+	resp := s.healthResponse()
+
+	code := http.StatusOK
+
+	for _, component := range resp.Components {
+		if component.Stalled {
+			resp.Status = healthStatusUnavailable
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	writeHealthResponse(w, code, resp)
+}
+
+// handleReadyz reports whether the server is ready to serve requests, which is when every critical
+// component is healthy and the server is not shutting down.
+func (s *server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	resp := s.healthResponse()
+
+	code := http.StatusOK
+
+	if overall, _ := s.health.status(overallService); overall != health.HealthCheckResponse_SERVING {
+		resp.Status = healthStatusUnavailable
+		code = http.StatusServiceUnavailable
+	}
+
+	writeHealthResponse(w, code, resp)
+}
+
+func (s *server) healthResponse() healthResponse {
+	resp := healthResponse{
+		Status:     healthStatusOK,
+		Components: make(map[string]componentHealth),
+	}
+
+	for name, status := range s.health.services() {
+		result := s.health.result(name)
+
+		component := componentHealth{
+			Status:    status.String(),
+			Critical:  s.health.critical(name),
+			Stalled:   s.health.stalled(name),
+			LastCheck: result.checked,
+		}
+
+		if result.err != nil {
+			component.Error = result.err.Error()
+		}
+
+		resp.Components[name] = component
+	}
+
+	return resp
+}
+
+func writeHealthResponse(w http.ResponseWriter, code int, resp healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	_ = json.NewEncoder(w).Encode(resp) //nolint:errcheck // error check not needed
+}
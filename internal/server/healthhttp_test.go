@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestHealthHTTP(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	testCases := []struct {
+		name          string
+		setup         func(m *healthManager)
+		expectLive    int
+		expectReady   int
+		expectError   string
+		expectStalled bool
+	}{
+		{
+			"healthy",
+			func(m *healthManager) {
+				m.record("jwt", nil)
+				m.record("events", nil)
+			},
+			http.StatusOK, http.StatusOK, "", false,
+		},
+		{
+			"non-critical failure",
+			func(m *healthManager) {
+				m.record("jwt", nil)
+				m.record("events", errUnhealthy)
+			},
+			http.StatusOK, http.StatusOK, "", false,
+		},
+		{
+			"critical failure",
+			func(m *healthManager) {
+				m.record("jwt", errUnhealthy)
+				m.record("events", nil)
+			},
+			http.StatusOK, http.StatusServiceUnavailable, "unhealthy", false,
+		},
+		{
+			"stalled",
+			func(m *healthManager) {
+				m.record("jwt", nil)
+				m.record("events", nil)
+
+				m.now = func() time.Time { return now.Add(-time.Hour) }
+				m.begin("jwt")
+				m.now = func() time.Time { return now }
+			},
+			http.StatusServiceUnavailable, http.StatusOK, "", true,
+		},
+		{
+			"shutting down",
+			func(m *healthManager) {
+				m.record("jwt", nil)
+				m.record("events", nil)
+				m.shutdown()
+			},
+			http.StatusOK, http.StatusServiceUnavailable, "", false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := newHealthManager(HealthChecks{
+				"jwt":    &testHealthChecker{},
+				"events": &testHealthChecker{},
+			}, []string{"events"}, time.Minute, time.Second, zap.NewNop().Sugar())
+
+			m.now = func() time.Time { return now }
+
+			tc.setup(m)
+
+			s := &server{
+				logger: zap.NewNop().Sugar(),
+				health: m,
+			}
+
+			live := httptest.NewRecorder()
+			s.handleLivez(live, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+			assert.Equal(t, tc.expectLive, live.Code, "unexpected livez status code")
+
+			ready := httptest.NewRecorder()
+			s.handleReadyz(ready, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tc.expectReady, ready.Code, "unexpected readyz status code")
+			assert.Equal(t, "application/json", ready.Header().Get("Content-Type"), "unexpected content type")
+
+			var resp healthResponse
+
+			require.NoError(t, json.NewDecoder(ready.Body).Decode(&resp), "no error expected decoding response")
+
+			require.Contains(t, resp.Components, "jwt", "expected jwt component")
+			require.Contains(t, resp.Components, "events", "expected events component")
+
+			assert.Equal(t, tc.expectError, resp.Components["jwt"].Error, "unexpected jwt error")
+			assert.Equal(t, tc.expectStalled, resp.Components["jwt"].Stalled, "unexpected jwt stalled")
+			assert.True(t, resp.Components["jwt"].Critical, "expected jwt to be critical")
+			assert.False(t, resp.Components["events"].Critical, "expected events to not be critical")
+
+			if tc.expectReady == http.StatusOK {
+				assert.Equal(t, healthStatusOK, resp.Status, "unexpected status")
+			} else {
+				assert.Equal(t, healthStatusUnavailable, resp.Status, "unexpected status")
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// overallService is the health service name reporting the status of the server as a whole.
+	overallService = ""
+
+	defaultHealthInterval = 10 * time.Second
+	defaultHealthTimeout  = 5 * time.Second
+)
+
+// healthManager probes health checkers in the background and caches the status of each service.
+// The overall status is serving when every critical service is serving.
+type healthManager struct {
+	logger      *zap.SugaredLogger
+	checks      HealthChecks
+	nonCritical map[string]bool
+	interval    time.Duration
+	timeout     time.Duration
+
+	mu       sync.RWMutex
+	statuses map[string]health.HealthCheckResponse_ServingStatus
+	results  map[string]healthResult
+	watchers map[string]map[chan health.HealthCheckResponse_ServingStatus]struct{}
+	stopped  bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	done   chan struct{}
+
+	now func() time.Time
+}
+
+// healthResult is the result of the last health check of a service.
+type healthResult struct {
+	// err is the error returned by the last health check.
+	err error
+	// checked is when the last health check completed.
+	checked time.Time
+	// started is when the in-flight health check started, zero when no check is in-flight.
+	started time.Time
+}
+
+func newHealthManager(checks HealthChecks, nonCritical []string, interval, timeout time.Duration, logger *zap.SugaredLogger) *healthManager {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+
+	if timeout <= 0 {
+		timeout = defaultHealthTimeout
+	}
+
+	m := &healthManager{
+		logger:      logger,
+		checks:      checks,
+		nonCritical: make(map[string]bool, len(nonCritical)),
+		interval:    interval,
+		timeout:     timeout,
+		statuses:    make(map[string]health.HealthCheckResponse_ServingStatus, len(checks)+1),
+		results:     make(map[string]healthResult, len(checks)),
+		watchers:    make(map[string]map[chan health.HealthCheckResponse_ServingStatus]struct{}),
+		cancel:      func() {},
+		done:        make(chan struct{}),
+		now:         time.Now,
+	}
+
+	for _, name := range nonCritical {
+		m.nonCritical[name] = true
+	}
+
+	for name := range checks {
+		m.statuses[name] = health.HealthCheckResponse_UNKNOWN
+	}
+
+	m.statuses[overallService] = m.overallStatus()
+
+	return m
+}
+
+// start probes every health checker until the manager is shut down.
+func (m *healthManager) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.cancel = cancel
+
+	for name, checker := range m.checks {
+		m.wg.Add(1)
+
+		go func() {
+			defer m.wg.Done()
+
+			m.probe(ctx, name, checker)
+		}()
+	}
+}
+
+func (m *healthManager) probe(ctx context.Context, name string, checker HealthChecker) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.begin(name)
+
+		checkCtx, cancel := context.WithTimeout(ctx, m.timeout)
+
+		err := checker.HealthCheck(checkCtx)
+
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		m.record(name, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// begin records the start of a health check of the service.
+func (m *healthManager) begin(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := m.results[name]
+	result.started = m.now()
+
+	m.results[name] = result
+}
+
+// record records the result of a health check of the service, updating the overall status and
+// notifying watchers when either status changes.
+func (m *healthManager) record(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return
+	}
+
+	m.results[name] = healthResult{
+		err:     err,
+		checked: m.now(),
+	}
+
+	status := health.HealthCheckResponse_SERVING
+	if err != nil {
+		status = health.HealthCheckResponse_NOT_SERVING
+	}
+
+	if m.statuses[name] == status {
+		return
+	}
+
+	if err != nil {
+		m.logger.Warnw("service unhealthy", "service", name, "critical", !m.nonCritical[name], "error", err)
+	} else {
+		m.logger.Infow("service healthy", "service", name)
+	}
+
+	m.statuses[name] = status
+	m.notify(name, status)
+
+	if overall := m.overallStatus(); overall != m.statuses[overallService] {
+		m.statuses[overallService] = overall
+		m.notify(overallService, overall)
+	}
+}
+
+// overallStatus returns serving when every critical service is serving. The lock must be held.
+func (m *healthManager) overallStatus() health.HealthCheckResponse_ServingStatus {
+	for name := range m.checks {
+		if !m.nonCritical[name] && m.statuses[name] != health.HealthCheckResponse_SERVING {
+			return health.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	return health.HealthCheckResponse_SERVING
+}
+
+// notify sends the status to the watchers of the service, replacing any status not yet sent.
+// The lock must be held.
+func (m *healthManager) notify(name string, status health.HealthCheckResponse_ServingStatus) {
+	for ch := range m.watchers[name] {
+		select {
+		case <-ch:
+		default:
+		}
+
+		ch <- status
+	}
+}
+
+// status returns the cached status of the service and whether the service is known.
+func (m *healthManager) status(name string) (health.HealthCheckResponse_ServingStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status, ok := m.statuses[name]
+
+	return status, ok
+}
+
+// services returns the cached status of every service, excluding the overall status.
+func (m *healthManager) services() map[string]health.HealthCheckResponse_ServingStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := maps.Clone(m.statuses)
+
+	delete(out, overallService)
+
+	return out
+}
+
+// result returns the result of the last health check of the service.
+func (m *healthManager) result(name string) healthResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.results[name]
+}
+
+// stalled returns whether the in-flight health check of the service has not returned within twice
+// the health check timeout. As health checks are canceled at the timeout, a stalled health check
+// indicates the process is stuck.
+func (m *healthManager) stalled(name string) bool {
+	started := m.result(name).started
+
+	return !started.IsZero() && m.now().Sub(started) > 2*m.timeout
+}
+
+// critical returns whether the service affects the overall status.
+func (m *healthManager) critical(name string) bool {
+	return !m.nonCritical[name]
+}
+
+// watch returns a channel receiving the current status of the service and every status change.
+// The returned function must be called to stop watching.
+func (m *healthManager) watch(name string) (<-chan health.HealthCheckResponse_ServingStatus, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan health.HealthCheckResponse_ServingStatus, 1)
+
+	status, ok := m.statuses[name]
+	if !ok {
+		status = health.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+
+	ch <- status
+
+	if m.watchers[name] == nil {
+		m.watchers[name] = make(map[chan health.HealthCheckResponse_ServingStatus]struct{})
+	}
+
+	m.watchers[name][ch] = struct{}{}
+
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.watchers[name], ch)
+	}
+}
+
+// shutdown stops probing and reports every service as not serving.
+func (m *healthManager) shutdown() {
+	m.cancel()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stopped {
+		return
+	}
+
+	m.stopped = true
+
+	for name := range m.statuses {
+		m.statuses[name] = health.HealthCheckResponse_NOT_SERVING
+		m.notify(name, health.HealthCheckResponse_NOT_SERVING)
+	}
+}
+
+// close ends all watches, allowing the servers to stop gracefully.
+func (m *healthManager) close() {
+	m.shutdown()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case <-m.done:
+	default:
+		close(m.done)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var errUnhealthy = errors.New("unhealthy")
+
+// testHealthChecker reports the configured health check result.
+type testHealthChecker struct {
+	err atomic.Pointer[error]
+}
+
+func (c *testHealthChecker) set(err error) {
+	c.err.Store(&err)
+}
+
+func (c *testHealthChecker) HealthCheck(_ context.Context) error {
+	if err := c.err.Load(); err != nil {
+		return *err
+	}
+
+	return nil
+}
+
+func TestHealthManager(t *testing.T) {
+	t.Parallel()
+
+	critical := &testHealthChecker{}
+	optional := &testHealthChecker{}
+
+	optional.set(errUnhealthy)
+
+	m := newHealthManager(HealthChecks{
+		"critical": critical,
+		"optional": optional,
+	}, []string{"optional"}, 5*time.Millisecond, time.Second, zap.NewNop().Sugar())
+
+	s := &server{
+		logger: zap.NewNop().Sugar(),
+		health: m,
+	}
+
+	checkStatus := func(service string) health.HealthCheckResponse_ServingStatus {
+		resp, err := s.Check(context.Background(), &health.HealthCheckRequest{Service: service})
+		require.NoError(t, err, "no error expected checking health")
+
+		return resp.GetStatus()
+	}
+
+	assert.Equal(t, health.HealthCheckResponse_UNKNOWN, checkStatus("critical"), "expected unknown status before probing")
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, checkStatus(""), "expected not serving before probing")
+
+	updates, stop := m.watch("")
+	defer stop()
+
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, <-updates, "expected current status to be sent")
+
+	m.start()
+
+	assert.Equal(t, health.HealthCheckResponse_SERVING, <-updates, "expected serving once probed")
+	assert.Equal(t, health.HealthCheckResponse_SERVING, checkStatus("critical"), "unexpected critical status")
+	assert.Eventually(t, func() bool {
+		return checkStatus("optional") == health.HealthCheckResponse_NOT_SERVING
+	}, time.Second, time.Millisecond, "expected non-critical service to be reported individually")
+
+	critical.set(errUnhealthy)
+
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, <-updates, "expected critical failure to change the overall status")
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, checkStatus(""), "unexpected overall status")
+
+	critical.set(nil)
+
+	assert.Equal(t, health.HealthCheckResponse_SERVING, <-updates, "expected recovery to change the overall status")
+
+	_, err := s.Check(context.Background(), &health.HealthCheckRequest{Service: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "expected unknown service to not be found")
+
+	missing, stopMissing := m.watch("missing")
+	defer stopMissing()
+
+	assert.Equal(t, health.HealthCheckResponse_SERVICE_UNKNOWN, <-missing, "expected unknown service to be watchable")
+
+	m.shutdown()
+
+	assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, <-updates, "expected not serving after shutdown")
+	assert.Equal(t, map[string]health.HealthCheckResponse_ServingStatus{
+		"critical": health.HealthCheckResponse_NOT_SERVING,
+		"optional": health.HealthCheckResponse_NOT_SERVING,
+	}, m.services(), "expected every service to be not serving after shutdown")
+}
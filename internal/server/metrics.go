@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authentication"
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authorization"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/metrics"
+)
+
+const httpReadHeaderTimeout = 5 * time.Second
+
+// metricsUnaryInterceptor records the count and latency of every unary request by method and outcome.
+func metricsUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	metrics.ObserveGRPCRequest(info.FullMethod, requestOutcome(resp, err), time.Since(start))
+
+	return resp, err
+}
+
+// requestOutcome returns the result of responses which report one, otherwise the status code.
+func requestOutcome(resp any, err error) string {
+	if err != nil {
+		return status.Code(err).String()
+	}
+
+	switch r := resp.(type) {
+	case *authorization.CheckAccessResponse:
+		return r.GetResult().String()
+	case *authentication.ValidateCredentialResponse:
+		return r.GetResult().String()
+	default:
+		return codes.OK.String()
+	}
+}
+
+func (s *server) listenAndServeHTTP(errCh chan<- error) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("GET /livez", s.handleLivez)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+
+	listener, err := net.Listen("tcp", s.httpAddress)
+	if err != nil {
+		s.logger.Errorw("failed to listen on http address", "error", err)
+
+		return err
+	}
+
+	s.logger.Infow("starting http server", "address", s.httpAddress)
+
+	s.httpSrv = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: httpReadHeaderTimeout,
+	}
+
+	go func() {
+		if err := s.httpSrv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerCredentials holds the credentials of the process connected to the socket.
+type PeerCredentials struct {
+	credentials.CommonAuthInfo
+
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// AuthType implements [credentials.AuthInfo].
+func (PeerCredentials) AuthType() string {
+	return "peercred"
+}
+
+// peerCredentialsFromContext returns the socket peer credentials of the request.
+func peerCredentialsFromContext(ctx context.Context) (PeerCredentials, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return PeerCredentials{}, false
+	}
+
+	creds, ok := p.AuthInfo.(PeerCredentials)
+
+	return creds, ok
+}
+
+// peerAuthorizer allows socket peers whose user or group is in the allowlist.
+// All peers are allowed when both allowlists are empty. SO_PEERCRED only reports the primary
+// group of the peer, so supplementary groups are not matched.
+type peerAuthorizer struct {
+	uids []uint32
+	gids []uint32
+}
+
+func newPeerAuthorizer(uids, gids []int) peerAuthorizer {
+	var out peerAuthorizer
+
+	for _, uid := range uids {
+		out.uids = append(out.uids, uint32(uid)) //nolint:gosec // ids are validated to be positive
+	}
+
+	for _, gid := range gids {
+		out.gids = append(out.gids, uint32(gid)) //nolint:gosec // ids are validated to be positive
+	}
+
+	return out
+}
+
+func (a peerAuthorizer) enabled() bool {
+	return len(a.uids) != 0 || len(a.gids) != 0
+}
+
+func (a peerAuthorizer) allowed(creds PeerCredentials) bool {
+	if !a.enabled() {
+		return true
+	}
+
+	return slices.Contains(a.uids, creds.UID) || slices.Contains(a.gids, creds.GID)
+}
+
+// peerTransportCredentials reads the peer credentials of unix socket connections, rejecting
+// connections from peers which are not allowed. Other connections are passed through unchanged.
+type peerTransportCredentials struct {
+	credentials.TransportCredentials
+
+	authorizer peerAuthorizer
+}
+
+func newPeerTransportCredentials(authorizer peerAuthorizer) credentials.TransportCredentials {
+	return &peerTransportCredentials{
+		TransportCredentials: insecure.NewCredentials(),
+		authorizer:           authorizer,
+	}
+}
+
+// ServerHandshake implements [credentials.TransportCredentials].
+func (c *peerTransportCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return conn, nil, nil
+	}
+
+	creds, err := getPeerCredentials(unixConn)
+	if err != nil {
+		if c.authorizer.enabled() {
+			return nil, nil, fmt.Errorf("%w: %w", ErrPeerNotAllowed, err)
+		}
+
+		return conn, nil, nil
+	}
+
+	if !c.authorizer.allowed(creds) {
+		return nil, nil, fmt.Errorf("%w: pid %d uid %d gid %d", ErrPeerNotAllowed, creds.PID, creds.UID, creds.GID)
+	}
+
+	return conn, creds, nil
+}
+
+// Clone implements [credentials.TransportCredentials].
+func (c *peerTransportCredentials) Clone() credentials.TransportCredentials {
+	return &peerTransportCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		authorizer:           c.authorizer,
+	}
+}
+
+// peerUnaryInterceptor records the socket peer credentials of every request on the request span.
+func peerUnaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if creds, ok := peerCredentialsFromContext(ctx); ok {
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Int("peer.pid", int(creds.PID)),
+			attribute.Int64("peer.uid", int64(creds.UID)),
+			attribute.Int64("peer.gid", int64(creds.GID)),
+		)
+	}
+
+	return handler(ctx, req)
+}
+
+// requestLogger returns the server logger with the socket peer credentials of the request.
+func (s *server) requestLogger(ctx context.Context) *zap.SugaredLogger {
+	creds, ok := peerCredentialsFromContext(ctx)
+	if !ok {
+		return s.logger
+	}
+
+	return s.logger.With("peer_pid", creds.PID, "peer_uid", creds.UID, "peer_gid", creds.GID)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// peerCredentialsSupported reports whether socket peer credentials can be read on this platform.
+const peerCredentialsSupported = true
+
+// getPeerCredentials reads the credentials of the process connected to the socket using SO_PEERCRED.
+func getPeerCredentials(conn *net.UnixConn) (PeerCredentials, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return PeerCredentials{}, err
+	}
+
+	var (
+		ucred   *unix.Ucred
+		credErr error
+	)
+
+	if err := raw.Control(func(fd uintptr) {
+		ucred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED) //nolint:gosec // file descriptors fit in an int
+	}); err != nil {
+		return PeerCredentials{}, err
+	}
+
+	if credErr != nil {
+		return PeerCredentials{}, credErr
+	}
+
+	return PeerCredentials{
+		PID: ucred.Pid,
+		UID: ucred.Uid,
+		GID: ucred.Gid,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerTransportCredentials(t *testing.T) {
+	t.Parallel()
+
+	uid := os.Getuid()
+
+	testCases := []struct {
+		name        string
+		authorizer  peerAuthorizer
+		expectError error
+	}{
+		{"no allowlist", newPeerAuthorizer(nil, nil), nil},
+		{"allowed uid", newPeerAuthorizer([]int{uid}, nil), nil},
+		{"allowed gid", newPeerAuthorizer([]int{uid + 1}, []int{os.Getgid()}), nil},
+		{"not allowed", newPeerAuthorizer([]int{uid + 1}, nil), ErrPeerNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			socketPath := filepath.Join(t.TempDir(), "test.sock")
+
+			listener, err := net.Listen("unix", socketPath)
+			require.NoError(t, err, "no error expected listening")
+
+			t.Cleanup(func() { _ = listener.Close() })
+
+			client, err := net.Dial("unix", socketPath)
+			require.NoError(t, err, "no error expected dialing")
+
+			t.Cleanup(func() { _ = client.Close() })
+
+			conn, err := listener.Accept()
+			require.NoError(t, err, "no error expected accepting")
+
+			t.Cleanup(func() { _ = conn.Close() })
+
+			_, authInfo, err := newPeerTransportCredentials(tc.authorizer).ServerHandshake(conn)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+			require.IsType(t, PeerCredentials{}, authInfo, "expected peer credentials")
+
+			creds := authInfo.(PeerCredentials)
+
+			assert.Equal(t, int32(os.Getpid()), creds.PID, "unexpected pid") //nolint:gosec // pids fit in an int32
+			assert.Equal(t, uint32(uid), creds.UID, "unexpected uid")        //nolint:gosec // uids are positive
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package server
+
+import "net"
+
+// peerCredentialsSupported reports whether socket peer credentials can be read on this platform.
+const peerCredentialsSupported = false
+
+// getPeerCredentials is not supported on this platform.
+func getPeerCredentials(_ *net.UnixConn) (PeerCredentials, error) {
+	return PeerCredentials{}, ErrPeerCredentialsUnsupported
+}
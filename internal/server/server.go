@@ -2,21 +2,33 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"go.infratographer.com/x/events"
 	"go.infratographer.com/x/gidx"
 	"golang.org/x/oauth2"
 
 	"go.infratographer.com/iam-runtime-infratographer/internal/accesstoken"
+	"go.infratographer.com/iam-runtime-infratographer/internal/audit"
+	"go.infratographer.com/iam-runtime-infratographer/internal/degradation"
 	"go.infratographer.com/iam-runtime-infratographer/internal/eventsx"
+	"go.infratographer.com/iam-runtime-infratographer/internal/introspection"
 	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+	"go.infratographer.com/iam-runtime-infratographer/internal/otelx"
 	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+	"go.infratographer.com/iam-runtime-infratographer/internal/revocation"
 
+	gojwt "github.com/golang-jwt/jwt/v5"
 	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authentication"
 	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authorization"
 	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/identity"
@@ -36,21 +48,48 @@ import (
 type Server interface {
 	Listen() error
 	Stop()
+
+	// Shutdown reports the server as not serving, waits for the drain period and then gracefully
+	// stops the server. The server is stopped forcefully and ErrShutdownTimeout is returned when
+	// in-flight requests do not complete within the shutdown timeout or the context is canceled.
+	Shutdown(ctx context.Context) error
 }
 
 type server struct {
-	validator   jwt.Validator
-	permClient  permissions.Client
-	publisher   eventsx.Publisher
-	logger      *zap.SugaredLogger
-	socketPath  string
-	tokenSource oauth2.TokenSource
+	validator    jwt.Validator
+	introspector introspection.Introspector
+	permClient   permissions.Client
+	publisher    eventsx.Publisher
+	revocation   revocation.Checker
+	auditor      audit.Auditor
+	degradation  degradation.Policy
+	logger       *zap.SugaredLogger
+	socketPath   string
+	tokenSource  oauth2.TokenSource
+
+	socketMode     os.FileMode
+	socketUID      int
+	socketGID      int
+	peerAuthorizer peerAuthorizer
+
+	tcpAddress string
+	tcpTLS     *tlsReloader
+
+	prevalidateCredentials bool
+
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
+	shuttingDown    atomic.Bool
 
 	grpcSrv *grpc.Server
+	tcpSrv  *grpc.Server
 
 	healthAddress string
 	healthSrv     *grpc.Server
-	healthChecks  HealthChecks
+	health        *healthManager
+
+	httpAddress string
+	httpSrv     *http.Server
 
 	authentication.UnimplementedAuthenticationServer
 	authorization.UnimplementedAuthorizationServer
@@ -59,56 +98,222 @@ type server struct {
 }
 
 // NewServer creates a new runtime server.
-func NewServer(cfg Config, validator jwt.Validator, permClient permissions.Client, publisher eventsx.Publisher, tokenSource accesstoken.HealthyTokenSource, logger *zap.SugaredLogger) (Server, error) {
+func NewServer(cfg Config, validator jwt.Validator, introspector introspection.Introspector, permClient permissions.Client, publisher eventsx.Publisher, revocationChecker revocation.Checker, auditor audit.Auditor, degradationPolicy degradation.Policy, tokenSource accesstoken.HealthyTokenSource, logger *zap.SugaredLogger) (Server, error) {
 	out := &server{
 		validator:     validator,
+		introspector:  introspector,
 		permClient:    permClient,
 		publisher:     publisher,
+		revocation:    revocationChecker,
+		auditor:       auditor,
+		degradation:   degradationPolicy,
 		logger:        logger,
 		socketPath:    cfg.SocketPath,
 		tokenSource:   tokenSource,
+		socketUID:     socketID(cfg.SocketUID),
+		socketGID:     socketID(cfg.SocketGID),
 		healthAddress: cfg.HealthAddress,
+		httpAddress:   cfg.HTTPAddress,
+
+		drainPeriod:     cfg.DrainPeriod,
+		shutdownTimeout: cfg.ShutdownTimeout,
+
+		prevalidateCredentials: cfg.PrevalidateCredentials,
+	}
+
+	if cfg.SocketPath == "" && cfg.TCP.Address == "" {
+		return nil, ErrNoListeners
+	}
+
+	// Degraded decisions are made without permissions-api, so the credential must be validated locally.
+	if degradationPolicy != nil && degradationPolicy.Mode() != degradation.ModeFailClosed && !cfg.PrevalidateCredentials {
+		return nil, fmt.Errorf("%w: %s", ErrDegradationWithoutPrevalidation, degradationPolicy.Mode())
+	}
+
+	if cfg.SocketMode != "" {
+		mode, err := strconv.ParseUint(cfg.SocketMode, 8, 32)
+		if err != nil || mode > uint64(os.ModePerm) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSocketMode, cfg.SocketMode)
+		}
+
+		out.socketMode = os.FileMode(mode)
+	}
+
+	for _, id := range slices.Concat(cfg.AllowedPeerUIDs, cfg.AllowedPeerGIDs) {
+		if id < 0 {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidPeerID, id)
+		}
 	}
 
-	out.healthChecks = HealthChecks{
-		"server":      out,
-		"jwt":         validator,
-		"permissions": permClient,
-		"events":      publisher,
-		"accessToken": tokenSource,
+	out.peerAuthorizer = newPeerAuthorizer(cfg.AllowedPeerUIDs, cfg.AllowedPeerGIDs)
+
+	if out.peerAuthorizer.enabled() && !peerCredentialsSupported {
+		return nil, ErrPeerCredentialsUnsupported
+	}
+
+	if cfg.TCP.Address != "" {
+		// Peer credentials only exist for socket callers, so TCP callers would bypass the allowlist.
+		if out.peerAuthorizer.enabled() {
+			return nil, ErrPeerAllowlistWithTCP
+		}
+
+		if (cfg.TCP.Insecure || cfg.TCP.TLS.ClientCAFile == "") && !cfg.TCP.NoClientAuth {
+			return nil, ErrTLSClientCARequired
+		}
+
+		out.tcpAddress = cfg.TCP.Address
+
+		if !cfg.TCP.Insecure {
+			reloader, err := newTLSReloader(cfg.TCP.TLS, logger)
+			if err != nil {
+				return nil, err
+			}
+
+			out.tcpTLS = reloader
+		}
+	}
+
+	healthChecks := HealthChecks{
+		"server":             out,
+		"jwt":                validator,
+		"permissions":        permClient,
+		"permissionsCircuit": HealthCheckFunc(permClient.CircuitHealthCheck),
+		"events":             publisher,
+		"accessToken":        tokenSource,
+	}
+
+	for _, name := range cfg.Health.NonCritical {
+		if _, ok := healthChecks[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownHealthService, name)
+		}
 	}
 
+	out.health = newHealthManager(healthChecks, cfg.Health.NonCritical, cfg.Health.Interval, cfg.Health.Timeout, logger)
+
 	return out, nil
 }
 
+// socketID returns the configured socket owner id, or -1 to leave the owner unchanged when unset.
+func socketID(id *int) int {
+	if id == nil || *id < 0 {
+		return -1
+	}
+
+	return *id
+}
+
 func (s *server) Listen() error {
-	errCh := make(chan error, 2) //nolint:mnd
+	errCh := make(chan error, 4) //nolint:mnd
 
 	if err := s.listenAndServeHealth(errCh); err != nil {
 		return fmt.Errorf("error starting health service: %w", err)
 	}
 
+	if err := s.listenAndServeHTTP(errCh); err != nil {
+		return fmt.Errorf("error starting http service: %w", err)
+	}
+
 	if err := s.listenAndServe(errCh); err != nil {
 		return fmt.Errorf("error starting grpc service: %w", err)
 	}
 
+	s.health.start()
+
 	return <-errCh
 }
 
-func (s *server) listenAndServe(errCh chan<- error) error {
-	grpcSrv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+// newGRPCServer creates a gRPC server serving the IAM runtime API. The identity service, which
+// returns the runtime's access token, is only registered for authenticated callers.
+func (s *server) newGRPCServer(withIdentity bool) *grpc.Server {
+	grpcSrv := grpc.NewServer(
+		grpc.Creds(newPeerTransportCredentials(s.peerAuthorizer)),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(peerUnaryInterceptor, metricsUnaryInterceptor),
+	)
 	authorization.RegisterAuthorizationServer(grpcSrv, s)
 	authentication.RegisterAuthenticationServer(grpcSrv, s)
-	identity.RegisterIdentityServer(grpcSrv, s)
 	health.RegisterHealthServer(grpcSrv, s)
 
-	if _, err := os.Stat(s.socketPath); err == nil {
+	if withIdentity {
+		identity.RegisterIdentityServer(grpcSrv, s)
+	}
+
+	return grpcSrv
+}
+
+func (s *server) listenAndServe(errCh chan<- error) error {
+	var (
+		socketListener net.Listener
+		tcpListener    net.Listener
+		err            error
+	)
+
+	if s.socketPath != "" {
+		if socketListener, err = s.listenSocket(); err != nil {
+			return err
+		}
+	}
+
+	if s.tcpAddress != "" {
+		if tcpListener, err = s.listenTCP(); err != nil {
+			if socketListener != nil {
+				_ = socketListener.Close() //nolint:errcheck // error check not needed
+			}
+
+			return err
+		}
+	}
+
+	s.grpcSrv = s.newGRPCServer(true)
+
+	if socketListener != nil {
+		serve(s.grpcSrv, socketListener, errCh)
+	}
+
+	if tcpListener != nil {
+		// TCP clients without a verified client certificate are served by a separate server
+		// without the identity service.
+		if s.tcpClientAuth() {
+			serve(s.grpcSrv, tcpListener, errCh)
+		} else {
+			s.tcpSrv = s.newGRPCServer(false)
+
+			serve(s.tcpSrv, tcpListener, errCh)
+		}
+	}
+
+	return nil
+}
+
+// serve serves the listener in the background, sending the result to the error channel.
+func serve(srv *grpc.Server, listener net.Listener, errCh chan<- error) {
+	go func() {
+		defer listener.Close() //nolint:errcheck
+
+		errCh <- srv.Serve(listener)
+	}()
+}
+
+// tcpClientAuth returns true when TCP clients are required to present a verified client certificate.
+func (s *server) tcpClientAuth() bool {
+	return s.tcpTLS != nil && s.tcpTLS.clientCAFile != ""
+}
+
+func (s *server) listenSocket() (net.Listener, error) {
+	// Only stale sockets are removed, to avoid unlinking an unrelated file at a misconfigured path.
+	if info, err := os.Lstat(s.socketPath); err == nil {
+		if info.Mode().Type() != os.ModeSocket {
+			s.logger.Errorw("socket path is not a socket", "socket_path", s.socketPath, "mode", info.Mode().String())
+
+			return nil, fmt.Errorf("%w: %s", ErrSocketPathNotSocket, s.socketPath)
+		}
+
 		s.logger.Warnw("socket found, unlinking", "socket_path", s.socketPath)
 
 		if err := syscall.Unlink(s.socketPath); err != nil {
 			s.logger.Errorw("error unlinking socket", "error", err)
 
-			return err
+			return nil, err
 		}
 	}
 
@@ -116,22 +321,58 @@ func (s *server) listenAndServe(errCh chan<- error) error {
 	if err != nil {
 		s.logger.Errorw("failed to listen on socket", "error", err)
 
-		return err
+		return nil, err
 	}
 
-	s.logger.Infow("starting server", "address", s.socketPath)
+	if err := s.setSocketPermissions(); err != nil {
+		_ = listener.Close() //nolint:errcheck // error check not needed
 
-	s.grpcSrv = grpcSrv
+		s.logger.Errorw("failed to set socket permissions", "error", err)
 
-	go func() {
-		defer listener.Close() //nolint:errcheck
+		return nil, err
+	}
 
-		errCh <- s.grpcSrv.Serve(listener)
-	}()
+	s.logger.Infow("starting server", "address", s.socketPath, "peer_allowlist", s.peerAuthorizer.enabled())
+
+	return listener, nil
+}
+
+// setSocketPermissions sets the configured socket mode and ownership.
+func (s *server) setSocketPermissions() error {
+	if s.socketUID >= 0 || s.socketGID >= 0 {
+		if err := os.Chown(s.socketPath, s.socketUID, s.socketGID); err != nil {
+			return fmt.Errorf("failed to set socket owner: %w", err)
+		}
+	}
+
+	if s.socketMode != 0 {
+		if err := os.Chmod(s.socketPath, s.socketMode); err != nil {
+			return fmt.Errorf("failed to set socket mode: %w", err)
+		}
+	}
 
 	return nil
 }
 
+func (s *server) listenTCP() (net.Listener, error) {
+	listener, err := net.Listen("tcp", s.tcpAddress)
+	if err != nil {
+		s.logger.Errorw("failed to listen on tcp address", "error", err)
+
+		return nil, err
+	}
+
+	if s.tcpTLS == nil {
+		s.logger.Warnw("starting server without tls", "address", s.tcpAddress)
+
+		return listener, nil
+	}
+
+	s.logger.Infow("starting server", "address", s.tcpAddress, "mtls", s.tcpTLS.clientCAFile != "")
+
+	return tls.NewListener(listener, s.tcpTLS.TLSConfig()), nil
+}
+
 func (s *server) listenAndServeHealth(errCh chan<- error) error {
 	healthSrv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
 	health.RegisterHealthServer(healthSrv, s)
@@ -157,21 +398,135 @@ func (s *server) listenAndServeHealth(errCh chan<- error) error {
 }
 
 func (s *server) Stop() {
+	s.health.close()
+
 	if srv := s.grpcSrv; srv != nil {
 		s.grpcSrv = nil // clear to ensure health check reports not running.
 
 		srv.GracefulStop()
 	}
 
+	if srv := s.tcpSrv; srv != nil {
+		s.tcpSrv = nil
+
+		srv.GracefulStop()
+	}
+
 	if srv := s.healthSrv; srv != nil {
 		s.healthSrv = nil // clear to ensure health check reports not running.
 
 		srv.GracefulStop()
 	}
+
+	if srv := s.httpSrv; srv != nil {
+		s.httpSrv = nil
+
+		_ = srv.Close() //nolint:errcheck // error check not needed
+	}
+
+	if s.tcpTLS != nil {
+		_ = s.tcpTLS.Close() //nolint:errcheck // error check not needed
+	}
+}
+
+// Shutdown reports the server as not serving, waits for the drain period and then gracefully
+// stops the server. The server is stopped forcefully and ErrShutdownTimeout is returned when
+// in-flight requests do not complete within the shutdown timeout or the context is canceled.
+func (s *server) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+	s.health.shutdown()
+
+	s.logger.Infow("server shutting down, draining", "drain_period", s.drainPeriod)
+
+	timer := time.NewTimer(s.drainPeriod)
+
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+	case <-timer.C:
+	}
+
+	s.health.close()
+
+	ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+	defer cancel()
+
+	var err error
+
+	if srv := s.grpcSrv; srv != nil {
+		s.grpcSrv = nil
+
+		if !gracefulStop(ctx, srv) {
+			s.logger.Warnw("in-flight requests did not complete within the shutdown timeout, stopping server",
+				"shutdown_timeout", s.shutdownTimeout,
+			)
+
+			err = ErrShutdownTimeout
+		}
+	}
+
+	if srv := s.tcpSrv; srv != nil {
+		s.tcpSrv = nil
+
+		if !gracefulStop(ctx, srv) {
+			err = ErrShutdownTimeout
+		}
+	}
+
+	if srv := s.healthSrv; srv != nil {
+		s.healthSrv = nil
+
+		if !gracefulStop(ctx, srv) {
+			err = ErrShutdownTimeout
+		}
+	}
+
+	if srv := s.httpSrv; srv != nil {
+		s.httpSrv = nil
+
+		if shutdownErr := srv.Shutdown(ctx); shutdownErr != nil {
+			_ = srv.Close() //nolint:errcheck // error check not needed
+
+			err = ErrShutdownTimeout
+		}
+	}
+
+	if s.tcpTLS != nil {
+		_ = s.tcpTLS.Close() //nolint:errcheck // error check not needed
+	}
+
+	return err
+}
+
+// gracefulStop gracefully stops the grpc server, stopping it forcefully if the context is done
+// before in-flight requests complete. False is returned when the server was stopped forcefully.
+func gracefulStop(ctx context.Context, srv *grpc.Server) bool {
+	done := make(chan struct{})
+
+	go func() {
+		srv.GracefulStop()
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		srv.Stop()
+
+		<-done
+
+		return false
+	}
 }
 
 // HealthCheck returns nil when the service is healthy.
 func (s *server) HealthCheck(_ context.Context) error {
+	if s.shuttingDown.Load() {
+		return ErrServerShuttingDown
+	}
+
 	if s.grpcSrv == nil {
 		return fmt.Errorf("%w: grpc service not running", ErrServerNotRunning)
 	}
@@ -183,14 +538,14 @@ func (s *server) HealthCheck(_ context.Context) error {
 	return nil
 }
 
-// ValidateCredential ensures that the given credential is a valid JWT issued by the OIDC issuer
-// the runtime was configured with.
+// ValidateCredential ensures that the given credential is a valid JWT issued by one of the OIDC
+// issuers the runtime was configured with.
 func (s *server) ValidateCredential(ctx context.Context, req *authentication.ValidateCredentialRequest) (*authentication.ValidateCredentialResponse, error) {
 	span := trace.SpanFromContext(ctx)
 
-	s.logger.Info("received ValidateCredential request")
+	s.requestLogger(ctx).Info("received ValidateCredential request")
 
-	sub, claims, err := s.validator.ValidateToken(req.Credential)
+	sub, claims, err := s.validateCredential(ctx, req.Credential)
 	if err != nil {
 		if errors.Is(err, jwt.ErrServiceDisabled) {
 			span.SetStatus(tcodes.Error, err.Error())
@@ -198,10 +553,25 @@ func (s *server) ValidateCredential(ctx context.Context, req *authentication.Val
 			return nil, err
 		}
 
+		if errors.Is(err, introspection.ErrIntrospectionFailed) {
+			span.RecordError(err)
+			span.SetStatus(tcodes.Error, err.Error())
+
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
 		span.RecordError(err)
 
-		s.logger.Errorw("invalid token", "error", err)
+		s.requestLogger(ctx).Errorw("invalid token", "error", err, otelx.ContextField(ctx))
+
+		resp := &authentication.ValidateCredentialResponse{
+			Result: authentication.ValidateCredentialResponse_RESULT_INVALID,
+		}
+
+		return resp, nil
+	}
 
+	if err := s.checkRevoked(ctx, claims); err != nil {
 		resp := &authentication.ValidateCredentialResponse{
 			Result: authentication.ValidateCredentialResponse_RESULT_INVALID,
 		}
@@ -232,14 +602,14 @@ func (s *server) ValidateCredential(ctx context.Context, req *authentication.Val
 func (s *server) GetAccessToken(ctx context.Context, _ *identity.GetAccessTokenRequest) (*identity.GetAccessTokenResponse, error) {
 	span := trace.SpanFromContext(ctx)
 
-	s.logger.Infow("received GetAccessToken request")
+	s.requestLogger(ctx).Info("received GetAccessToken request")
 
 	token, err := s.tokenSource.Token()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(tcodes.Error, "failed to fetch token from token source: "+err.Error())
 
-		s.logger.Errorw("failed to fetch token from token source", "error", err)
+		s.requestLogger(ctx).Errorw("failed to fetch token from token source", "error", err, otelx.ContextField(ctx))
 
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -254,9 +624,36 @@ func (s *server) GetAccessToken(ctx context.Context, _ *identity.GetAccessTokenR
 // CheckAccess takes the given request and sends it to permissions-api, using the given credential
 // as a bearer token.
 func (s *server) CheckAccess(ctx context.Context, req *authorization.CheckAccessRequest) (*authorization.CheckAccessResponse, error) {
-	span := trace.SpanFromContext(ctx)
+	start := time.Now()
+
+	resp, details, err := s.checkAccess(ctx, req)
+
+	s.auditCheckAccess(ctx, req, resp, details, err, time.Since(start))
+
+	return resp, err
+}
 
-	s.logger.Info("received CheckAccess request")
+// checkAccess evaluates the request, returning how the decision was made.
+func (s *server) checkAccess(ctx context.Context, req *authorization.CheckAccessRequest) (*authorization.CheckAccessResponse, checkAccessDetails, error) {
+	s.requestLogger(ctx).Info("received CheckAccess request")
+
+	subject, err := s.prevalidateCredential(ctx, req.Credential)
+	if err != nil {
+		return nil, checkAccessDetails{}, err
+	}
+
+	resp, details, err := s.decideAccess(ctx, req, subject)
+
+	details.subject = subject
+
+	return resp, details, err
+}
+
+// decideAccess sends the request to permissions-api, falling back to the degradation policy when
+// permissions-api is unavailable. The subject is that of the locally validated credential, empty
+// when the credential was not validated.
+func (s *server) decideAccess(ctx context.Context, req *authorization.CheckAccessRequest, subject string) (*authorization.CheckAccessResponse, checkAccessDetails, error) {
+	span := trace.SpanFromContext(ctx)
 
 	actions := make([]permissions.RequestAction, 0, len(req.Actions))
 
@@ -268,6 +665,12 @@ func (s *server) CheckAccess(ctx context.Context, req *authorization.CheckAccess
 		actions = append(actions, action)
 	}
 
+	if perActionRequested(ctx) {
+		return s.checkAccessPerAction(ctx, req.Credential, subject, actions)
+	}
+
+	span.SetAttributes(attribute.String("checkaccess.mode", checkModeAll))
+
 	err := s.permClient.CheckAccess(ctx, req.Credential, actions)
 
 	// Per the IAM runtime spec, a 401 from permissions-api should result in an InvalidArgument
@@ -276,31 +679,124 @@ func (s *server) CheckAccess(ctx context.Context, req *authorization.CheckAccess
 	case err == nil:
 		span.AddEvent("allowed")
 
+		s.degradation.Record(req.Credential, actions, slices.Repeat([]bool{true}, len(actions)))
+
 		out := &authorization.CheckAccessResponse{
 			Result: authorization.CheckAccessResponse_RESULT_ALLOWED,
 		}
 
-		return out, nil
-	case errors.Is(err, permissions.ErrUnauthenticated):
-		span.RecordError(err)
-
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return out, checkAccessDetails{}, nil
 	case errors.Is(err, permissions.ErrPermissionDenied):
 		span.AddEvent("denied")
 
+		// Which action was denied is only known when a single action was requested.
+		if len(actions) == 1 {
+			s.degradation.Record(req.Credential, actions, []bool{false})
+		}
+
 		out := &authorization.CheckAccessResponse{
 			Result: authorization.CheckAccessResponse_RESULT_DENIED,
 		}
 
-		return out, nil
+		return out, checkAccessDetails{}, nil
+	default:
+		if out, details, ok := s.checkAccessDegraded(ctx, req.Credential, subject, actions, false, err); ok {
+			return out, details, nil
+		}
+
+		return nil, checkAccessDetails{}, checkAccessError(span, err)
+	}
+}
+
+// validateCredential validates the credential as a JWT. Credentials which are not JWTs are
+// validated using token introspection when enabled.
+func (s *server) validateCredential(ctx context.Context, credential string) (string, map[string]any, error) {
+	sub, claims, err := s.validator.ValidateToken(credential)
+	if err == nil || !errors.Is(err, gojwt.ErrTokenMalformed) {
+		return sub, claims, err
+	}
+
+	isub, iclaims, ierr := s.introspector.Introspect(ctx, credential)
+	if errors.Is(ierr, introspection.ErrServiceDisabled) {
+		return sub, claims, err
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("credential introspected")
+
+	return isub, iclaims, ierr
+}
+
+// prevalidateCredential validates the credential locally when enabled, avoiding a round trip to
+// permissions-api for credentials which are expired or malformed. The subject of a validated
+// credential is returned, empty when the credential was not validated.
+// Per the IAM runtime spec, an invalid credential results in an InvalidArgument status.
+func (s *server) prevalidateCredential(ctx context.Context, credential string) (string, error) {
+	if !s.prevalidateCredentials {
+		return "", nil
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	sub, claims, err := s.validateCredential(ctx, credential)
+	if err == nil {
+		err = s.checkRevoked(ctx, claims)
+	}
+
+	switch {
+	case err == nil:
+		span.AddEvent("credential prevalidated")
+
+		return sub, nil
+	case errors.Is(err, jwt.ErrServiceDisabled):
+		s.logger.Debug("credential prevalidation skipped, jwt service disabled")
+
+		return "", nil
+	case errors.Is(err, introspection.ErrIntrospectionFailed):
+		span.RecordError(err)
+
+		return "", status.Error(codes.Unavailable, err.Error())
 	default:
 		span.RecordError(err)
-		span.SetStatus(tcodes.Error, "unexpected error: "+err.Error())
+		span.AddEvent("credential rejected")
+
+		s.requestLogger(ctx).Infow("credential rejected by prevalidation", "error", err, otelx.ContextField(ctx))
 
-		return nil, status.Error(codes.Unavailable, err.Error())
+		return "", status.Error(codes.InvalidArgument, err.Error())
 	}
 }
 
+// checkRevoked returns an error when the validated token claims have been revoked.
+func (s *server) checkRevoked(ctx context.Context, claims map[string]any) error {
+	err := s.revocation.CheckRevoked(ctx, claims)
+	if err == nil {
+		return nil
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	span.AddEvent("credential revoked", trace.WithAttributes(
+		attribute.String("revocation.reason", err.Error()),
+	))
+
+	s.requestLogger(ctx).Infow("revoked token", "error", err, otelx.ContextField(ctx))
+
+	return err
+}
+
+// checkAccessError converts a permissions client error into a gRPC status error.
+func checkAccessError(span trace.Span, err error) error {
+	if errors.Is(err, permissions.ErrUnauthenticated) {
+		span.RecordError(err)
+
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	span.RecordError(err)
+	span.SetStatus(tcodes.Error, "unexpected error: "+err.Error())
+
+	return status.Error(codes.Unavailable, err.Error())
+}
+
 func buildAuthRelations(rels []*authorization.Relationship) ([]events.AuthRelationshipRelation, error) {
 	out := make([]events.AuthRelationshipRelation, len(rels))
 
@@ -348,7 +844,7 @@ func (s *server) publishRelationships(ctx context.Context, action events.AuthRel
 		Relations: relations,
 	}
 
-	s.logger.Infow("request", "req", authReq)
+	s.requestLogger(ctx).Infow("request", "req", authReq, otelx.ContextField(ctx))
 
 	authResp, err := s.publisher.PublishAuthRelationshipRequest(ctx, authReq)
 	if err != nil {
@@ -377,7 +873,7 @@ func (s *server) publishRelationships(ctx context.Context, action events.AuthRel
 // CreateRelationships publishes the relationships provided to permissions-api with a write operation
 // via NATS and waits for a reply.
 func (s *server) CreateRelationships(ctx context.Context, req *authorization.CreateRelationshipsRequest) (*authorization.CreateRelationshipsResponse, error) {
-	s.logger.Info("received CreateRelationships request")
+	s.requestLogger(ctx).Info("received CreateRelationships request")
 
 	err := s.publishRelationships(ctx, events.WriteAuthRelationshipAction, req.ResourceId, req.Relationships)
 	if err != nil {
@@ -392,7 +888,7 @@ func (s *server) CreateRelationships(ctx context.Context, req *authorization.Cre
 // CreateRelationships publishes the relationships provided to permissions-api with a delete operation
 // via NATS and waits for a reply.
 func (s *server) DeleteRelationships(ctx context.Context, req *authorization.DeleteRelationshipsRequest) (*authorization.DeleteRelationshipsResponse, error) {
-	s.logger.Info("received DeleteRelationships request")
+	s.requestLogger(ctx).Info("received DeleteRelationships request")
 
 	err := s.publishRelationships(ctx, events.DeleteAuthRelationshipAction, req.ResourceId, req.Relationships)
 	if err != nil {
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// blockingHealthServer blocks health checks until released, simulating in-flight requests.
+type blockingHealthServer struct {
+	health.UnimplementedHealthServer
+
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingHealthServer) Check(ctx context.Context, _ *health.HealthCheckRequest) (*health.HealthCheckResponse, error) {
+	close(s.started)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.release:
+	}
+
+	return &health.HealthCheckResponse{Status: health.HealthCheckResponse_SERVING}, nil
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		release     bool
+		expectError error
+	}{
+		{"graceful", true, nil},
+		{"timeout", false, ErrShutdownTimeout},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			blocking := &blockingHealthServer{
+				started: make(chan struct{}),
+				release: make(chan struct{}),
+			}
+
+			grpcSrv := grpc.NewServer()
+			health.RegisterHealthServer(grpcSrv, blocking)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err, "no error expected listening")
+
+			go grpcSrv.Serve(listener) //nolint:errcheck // error check not needed
+
+			conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err, "no error expected creating client")
+
+			t.Cleanup(func() { _ = conn.Close() })
+
+			callErr := make(chan error, 1)
+
+			go func() {
+				_, err := health.NewHealthClient(conn).Check(context.Background(), &health.HealthCheckRequest{})
+
+				callErr <- err
+			}()
+
+			<-blocking.started
+
+			s := &server{
+				logger:          zap.NewNop().Sugar(),
+				drainPeriod:     50 * time.Millisecond,
+				shutdownTimeout: 50 * time.Millisecond,
+				grpcSrv:         grpcSrv,
+				healthSrv:       grpc.NewServer(),
+				health:          newHealthManager(nil, nil, 0, 0, zap.NewNop().Sugar()),
+			}
+
+			require.NoError(t, s.HealthCheck(context.Background()), "expected server to be healthy before shutdown")
+
+			shutdownErr := make(chan error, 1)
+
+			go func() {
+				shutdownErr <- s.Shutdown(context.Background())
+			}()
+
+			require.Eventually(t, s.shuttingDown.Load, time.Second, time.Millisecond, "expected shutdown to start")
+
+			assert.ErrorIs(t, s.HealthCheck(context.Background()), ErrServerShuttingDown, "expected health check to fail while draining")
+
+			resp, err := s.Check(context.Background(), &health.HealthCheckRequest{})
+			require.NoError(t, err, "no error expected checking health")
+			assert.Equal(t, health.HealthCheckResponse_NOT_SERVING, resp.GetStatus(), "expected not serving while draining")
+
+			if tc.release {
+				close(blocking.release)
+			}
+
+			err = <-shutdownErr
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected shutdown error")
+				assert.Error(t, <-callErr, "expected in-flight request to be aborted")
+
+				return
+			}
+
+			assert.NoError(t, err, "no error expected shutting down")
+			assert.NoError(t, <-callErr, "expected in-flight request to complete")
+		})
+	}
+}
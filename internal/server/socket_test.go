@@ -0,0 +1,62 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestListenSocket(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	s := &server{
+		logger:     zap.NewNop().Sugar(),
+		socketPath: filepath.Join(dir, "runtime.sock"),
+		socketMode: 0o600,
+		socketUID:  -1,
+		socketGID:  -1,
+	}
+
+	listener, err := s.listenSocket()
+	require.NoError(t, err, "no error expected listening")
+
+	info, err := os.Stat(s.socketPath)
+	require.NoError(t, err, "no error expected getting socket info")
+
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "unexpected socket mode")
+
+	// Closing a unix listener removes the socket, so a stale socket is simulated by disabling unlinking.
+	listener.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close(), "no error expected closing listener")
+
+	listener, err = s.listenSocket()
+	require.NoError(t, err, "expected stale socket to be replaced")
+	require.NoError(t, listener.Close(), "no error expected closing listener")
+
+	s.socketPath = filepath.Join(dir, "not-a-socket")
+
+	require.NoError(t, os.WriteFile(s.socketPath, []byte("data"), 0o600), "no error expected writing file")
+
+	_, err = s.listenSocket()
+	assert.ErrorIs(t, err, ErrSocketPathNotSocket, "expected regular file to not be unlinked")
+
+	_, err = os.Stat(s.socketPath)
+	assert.NoError(t, err, "expected regular file to remain")
+}
+
+func TestSocketID(t *testing.T) {
+	t.Parallel()
+
+	unset, negative, root, user := (*int)(nil), -1, 0, 1000
+
+	assert.Equal(t, -1, socketID(unset), "expected unset id to leave the owner unchanged")
+	assert.Equal(t, -1, socketID(&negative), "expected negative id to leave the owner unchanged")
+	assert.Equal(t, 0, socketID(&root), "expected root id to be kept")
+	assert.Equal(t, 1000, socketID(&user), "expected id to be kept")
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// tlsReloader serves the TCP listener TLS configuration, reloading the certificates when they change.
+type tlsReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	config  atomic.Pointer[tls.Config]
+	watcher *fsnotify.Watcher
+	logger  *zap.SugaredLogger
+}
+
+// newTLSReloader loads the certificates and starts watching them for changes.
+func newTLSReloader(config TLSConfig, logger *zap.SugaredLogger) (*tlsReloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, ErrTLSCertificateRequired
+	}
+
+	out := &tlsReloader{
+		certFile: filepath.Clean(config.CertFile),
+		keyFile:  filepath.Clean(config.KeyFile),
+		logger:   logger.With("component", "tls"),
+	}
+
+	if config.ClientCAFile != "" {
+		out.clientCAFile = filepath.Clean(config.ClientCAFile)
+	}
+
+	if err := out.load(); err != nil {
+		return nil, err
+	}
+
+	// The parent directories are watched instead of the files themselves so replacements,
+	// such as atomic renames and Kubernetes secret symlink swaps, are detected.
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tls certificate watcher: %w", err)
+	}
+
+	for _, file := range []string{out.certFile, out.keyFile, out.clientCAFile} {
+		if file == "" {
+			continue
+		}
+
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			_ = watcher.Close() //nolint:errcheck // error check not needed
+
+			return nil, fmt.Errorf("failed to watch tls certificate: %w", err)
+		}
+	}
+
+	out.watcher = watcher
+
+	go out.watch()
+
+	return out, nil
+}
+
+// load reads the certificates and replaces the served TLS configuration.
+func (r *tlsReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"h2"},
+	}
+
+	if r.clientCAFile != "" {
+		pem, err := os.ReadFile(r.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tls client ca: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("%w: %s", ErrTLSClientCAInvalid, r.clientCAFile)
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.config.Store(config)
+
+	return nil
+}
+
+// watch reloads the certificates whenever their directories change.
+func (r *tlsReloader) watch() {
+	for {
+		select {
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+
+			if err := r.load(); err != nil {
+				r.logger.Warnw("failed to reload tls certificates", "error", err)
+
+				continue
+			}
+
+			r.logger.Debug("tls certificates reloaded")
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+
+			r.logger.Warnw("tls certificate watcher error", "error", err)
+		}
+	}
+}
+
+// TLSConfig returns a TLS configuration which always uses the most recently loaded certificates.
+func (r *tlsReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config.Load(), nil
+		},
+	}
+}
+
+// Close stops watching the certificates.
+func (r *tlsReloader) Close() error {
+	return r.watcher.Close()
+}
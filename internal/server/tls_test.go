@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/authentication"
+	"github.com/metal-toolbox/iam-runtime/pkg/iam/runtime/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"go.infratographer.com/iam-runtime-infratographer/internal/jwt"
+	"go.infratographer.com/iam-runtime-infratographer/internal/permissions"
+)
+
+func writeTestCertificate(t *testing.T, dir, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "no error expected generating key")
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		DNSNames:     []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "no error expected creating certificate")
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "no error expected marshaling key")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	// Write to temporary files and rename so the pair is replaced atomically.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key.tmp"), keyPEM, 0o600), "no error expected writing key")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt.tmp"), certPEM, 0o600), "no error expected writing certificate")
+	require.NoError(t, os.Rename(filepath.Join(dir, "tls.key.tmp"), filepath.Join(dir, "tls.key")), "no error expected renaming key")
+	require.NoError(t, os.Rename(filepath.Join(dir, "tls.crt.tmp"), filepath.Join(dir, "tls.crt")), "no error expected renaming certificate")
+}
+
+func servedCommonName(t *testing.T, reloader *tlsReloader) string {
+	t.Helper()
+
+	config, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	require.NoError(t, err, "no error expected getting config")
+	require.Len(t, config.Certificates, 1, "expected a certificate")
+
+	cert, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	require.NoError(t, err, "no error expected parsing certificate")
+
+	return cert.Subject.CommonName
+}
+
+func TestTLSReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeTestCertificate(t, dir, "first")
+
+	reloader, err := newTLSReloader(TLSConfig{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "tls.crt"),
+	}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating reloader")
+
+	t.Cleanup(func() { _ = reloader.Close() })
+
+	assert.Equal(t, "first", servedCommonName(t, reloader), "unexpected certificate served")
+	assert.Equal(t, tls.RequireAndVerifyClientCert, reloader.config.Load().ClientAuth, "expected client certificates to be required")
+
+	writeTestCertificate(t, dir, "second")
+
+	assert.Eventually(t, func() bool {
+		return servedCommonName(t, reloader) == "second"
+	}, 5*time.Second, 10*time.Millisecond, "expected certificate to be reloaded")
+}
+
+func TestNewTLSReloaderErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeTestCertificate(t, dir, "test")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.pem"), []byte("not a certificate"), 0o600), "no error expected writing file")
+
+	testCases := []struct {
+		name        string
+		config      TLSConfig
+		expectError error
+	}{
+		{"missing certificate", TLSConfig{}, ErrTLSCertificateRequired},
+		{"invalid client ca", TLSConfig{
+			CertFile:     filepath.Join(dir, "tls.crt"),
+			KeyFile:      filepath.Join(dir, "tls.key"),
+			ClientCAFile: filepath.Join(dir, "empty.pem"),
+		}, ErrTLSClientCAInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newTLSReloader(tc.config, zap.NewNop().Sugar())
+
+			assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+		})
+	}
+}
+
+func TestNewServerTCP(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	writeTestCertificate(t, dir, "test")
+
+	permClient, err := permissions.NewClient(permissions.Config{Disable: true}, zap.NewNop().Sugar())
+	require.NoError(t, err, "no error expected creating permissions client")
+
+	serverTLS := TLSConfig{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	}
+
+	mutualTLS := serverTLS
+	mutualTLS.ClientCAFile = filepath.Join(dir, "tls.crt")
+
+	testCases := []struct {
+		name        string
+		config      Config
+		expectError error
+	}{
+		{
+			"mtls",
+			Config{TCP: TCPConfig{Address: "127.0.0.1:0", TLS: mutualTLS}},
+			nil,
+		},
+		{
+			"tls without client ca",
+			Config{TCP: TCPConfig{Address: "127.0.0.1:0", TLS: serverTLS}},
+			ErrTLSClientCARequired,
+		},
+		{
+			"tls without client auth",
+			Config{TCP: TCPConfig{Address: "127.0.0.1:0", TLS: serverTLS, NoClientAuth: true}},
+			nil,
+		},
+		{
+			"insecure",
+			Config{TCP: TCPConfig{Address: "127.0.0.1:0", Insecure: true}},
+			ErrTLSClientCARequired,
+		},
+		{
+			"insecure without client auth",
+			Config{TCP: TCPConfig{Address: "127.0.0.1:0", Insecure: true, NoClientAuth: true}},
+			nil,
+		},
+		{
+			"allowed peers with tcp",
+			Config{AllowedPeerUIDs: []int{1000}, TCP: TCPConfig{Address: "127.0.0.1:0", TLS: mutualTLS}},
+			ErrPeerAllowlistWithTCP,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if !peerCredentialsSupported && len(tc.config.AllowedPeerUIDs) != 0 {
+				t.Skip("socket peer credentials are not supported on this platform")
+			}
+
+			srv, err := NewServer(tc.config, nil, nil, permClient, nil, nil, nil, nil, nil, zap.NewNop().Sugar())
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError, "unexpected error returned")
+
+				return
+			}
+
+			require.NoError(t, err, "no error expected")
+
+			srv.Stop()
+		})
+	}
+}
+
+func TestListenTCPIdentity(t *testing.T) {
+	t.Parallel()
+
+	// Reserve a free port for the TCP listener.
+	reserved, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "no error expected listening")
+
+	address := reserved.Addr().String()
+
+	require.NoError(t, reserved.Close(), "no error expected closing listener")
+
+	s := &server{
+		logger:         zap.NewNop().Sugar(),
+		validator:      &testValidator{err: jwt.ErrServiceDisabled},
+		tcpAddress:     address,
+		peerAuthorizer: newPeerAuthorizer(nil, nil),
+	}
+
+	errCh := make(chan error, 1)
+
+	require.NoError(t, s.listenAndServe(errCh), "no error expected serving")
+
+	t.Cleanup(s.tcpSrv.Stop)
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "no error expected creating client")
+
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = identity.NewIdentityClient(conn).GetAccessToken(context.Background(), &identity.GetAccessTokenRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "expected identity service to not be served without mtls")
+
+	_, err = authentication.NewAuthenticationClient(conn).ValidateCredential(context.Background(), &authentication.ValidateCredentialRequest{})
+	assert.NotEqual(t, codes.Unimplemented, status.Code(err), "expected authentication service to be served")
+}
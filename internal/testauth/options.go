@@ -32,3 +32,10 @@ func NotBefore(v *jwt.NumericDate) ClaimOption {
 		c.NotBefore = v
 	}
 }
+
+// Issuer lets you specify an issuer claim option.
+func Issuer(v string) ClaimOption {
+	return func(c *jwt.Claims) {
+		c.Issuer = v
+	}
+}
@@ -99,7 +99,10 @@ func (s *Server) serve(srv *http.Server, listener net.Listener) {
 
 func (s *Server) handleOIDC(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{
-		"jwks_uri": s.Issuer + "/.well-known/jwks.json",
+		"issuer":                                s.Issuer,
+		"jwks_uri":                              s.Issuer + "/.well-known/jwks.json",
+		"token_endpoint":                        s.Issuer + "/token",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
 	})
 }
 
@@ -142,6 +145,21 @@ func (s *Server) TSignSubject(t *testing.T, subject string, options ...ClaimOpti
 	return token
 }
 
+// TSignSubjectWithClaims returns a new token string with the provided subject and additional private claims.
+// Additional registered claims may be provided as options.
+// Any errors produced will result in the passed test argument failing.
+func (s *Server) TSignSubjectWithClaims(t *testing.T, subject string, privateClaims map[string]any, options ...ClaimOption) string {
+	options = append(options, Subject(subject))
+
+	claims := s.buildClaims(options...).Claims(privateClaims)
+
+	token, err := claims.Serialize()
+
+	require.NoError(t, err)
+
+	return token
+}
+
 // SignSubject returns a new token string with the provided subject.
 // Additional claims may be provided as options.
 // Any errors produced will result in the test passed when initializing Server to fail.